@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"flag"
 	"log/slog"
 
 	"github.com/mithileshgupta12/velaris/internal/cache"
@@ -11,9 +12,31 @@ import (
 	"github.com/mithileshgupta12/velaris/internal/route"
 )
 
+// Execute runs the command named by the first argument after the global
+// flags. Without a command the API server is started; background work runs
+// in the worker command.
 func Execute() {
 	cfg := config.NewConfig()
 
+	switch command := flag.Arg(0); command {
+	case "", "serve":
+		serve(cfg)
+	case "worker":
+		worker(cfg, flag.Args()[1:])
+	case "import-trello":
+		importTrello(cfg, flag.Args()[1:])
+	case "openapi":
+		openAPI(flag.Args()[1:])
+	default:
+		helper.LogFatal("unknown command", "command", command)
+	}
+}
+
+func serve(cfg *config.Config) {
+	if cfg.App.Key == "" {
+		helper.LogFatal("app key is required to sign links", "flag", "-app-key")
+	}
+
 	repositories, policies, err := db.NewDB(&cfg.DB)
 	if err != nil {
 		helper.LogFatal("failed to connect to database", "err", err)
@@ -31,10 +54,18 @@ func Execute() {
 	slog.Info("Connection to cache successful")
 	defer cache.Close()
 
-	middlewares := middleware.NewMiddlewares(repositories, stores.SessionStore)
+	middlewares := middleware.NewMiddlewares(repositories, stores.SessionStore, stores.IdempotencyStore)
 
-	r := route.NewRouter(cfg.App.FrontendUrl)
+	r := route.NewRouter(&cfg.App)
 	r.RegisterRoutes(repositories, policies, stores, middlewares)
+
+	missing, err := r.VerifyDocument(route.APIDocument())
+	if err != nil {
+		helper.LogFatal("failed to verify openapi document", "err", err)
+	}
+	for _, route := range missing {
+		slog.Warn("route missing from openapi document", "route", route)
+	}
 	if err := r.Serve(cfg.App.Port); err != nil {
 		helper.LogFatal("failed to start server", "err", err)
 	}
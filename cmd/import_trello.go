@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/transfer"
+)
+
+// importTrello imports Trello board exports given as file arguments:
+//
+//	velaris [global flags] import-trello -user-email=jane@example.com [-dry-run] board.json...
+func importTrello(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("import-trello", flag.ExitOnError)
+	userEmail := flags.String("user-email", "", "Email of the user who will own the imported boards")
+	dryRun := flags.Bool("dry-run", false, "Report what would be imported without creating anything")
+
+	if err := flags.Parse(args); err != nil {
+		helper.LogFatal("failed to parse flags", "err", err)
+	}
+
+	if *userEmail == "" || flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: velaris import-trello -user-email=EMAIL [-dry-run] FILE...")
+		os.Exit(2)
+	}
+
+	repositories, _, err := db.NewDB(&cfg.DB)
+	if err != nil {
+		helper.LogFatal("failed to connect to database", "err", err)
+	}
+
+	user, err := repositories.GetUserByEmail(strings.ToLower(strings.TrimSpace(*userEmail)))
+	if err != nil {
+		helper.LogFatal("failed to get user by email", "email", *userEmail, "err", err)
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		if err := importTrelloFile(repositories.BoardRepository, path, user.Id, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func importTrelloFile(boardRepository repository.BoardRepository, path string, userId int64, dryRun bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	trelloBoard, err := transfer.DecodeTrelloBoard(file)
+	if err != nil {
+		return err
+	}
+
+	createBoardArgs, report, err := transfer.PlanTrelloImport(trelloBoard, userId)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		report.DryRun = true
+	} else {
+		board, err := boardRepository.CreateBoard(createBoardArgs)
+		if err != nil {
+			return err
+		}
+
+		report.SetCreated(board)
+	}
+
+	printTrelloReport(path, report)
+
+	return nil
+}
+
+func printTrelloReport(path string, report *transfer.TrelloImportReport) {
+	verb := "imported"
+	if report.DryRun {
+		verb = "would import"
+	}
+
+	fmt.Printf("%s: %s board %q with %d lists", path, verb, report.Board.Name, len(report.Lists))
+	if report.Board.Id != 0 {
+		fmt.Printf(" (board id %d)", report.Board.Id)
+	}
+	fmt.Println()
+
+	for _, list := range report.Lists {
+		state := ""
+		if list.Archived {
+			state = " [archived]"
+		}
+		fmt.Printf("  list %d: %s%s\n", list.Position, list.Name, state)
+	}
+
+	for _, skipped := range report.Skipped {
+		switch {
+		case skipped.Count > 0:
+			fmt.Printf("  skipped %d %ss: %s\n", skipped.Count, skipped.Kind, skipped.Reason)
+		case skipped.Name != "":
+			fmt.Printf("  skipped %s %q: %s\n", skipped.Kind, skipped.Name, skipped.Reason)
+		default:
+			fmt.Printf("  skipped %s %s: %s\n", skipped.Kind, skipped.TrelloId, skipped.Reason)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/route"
+)
+
+// openAPI prints the OpenAPI document, or with -check fails when a registered
+// route is missing from it, as the route package tests do in CI:
+//
+//	velaris [global flags] openapi [-check]
+func openAPI(args []string) {
+	flags := flag.NewFlagSet("openapi", flag.ExitOnError)
+	check := flags.Bool("check", false, "Only verify that every registered route is documented")
+
+	if err := flags.Parse(args); err != nil {
+		helper.LogFatal("failed to parse flags", "err", err)
+	}
+
+	doc := route.APIDocument()
+
+	if !*check {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(doc); err != nil {
+			helper.LogFatal("failed to encode openapi document", "err", err)
+		}
+		return
+	}
+
+	// Handlers are only constructed, never called, so the routes can be
+	// registered without a database or cache.
+	r := route.NewRouter(&config.AppFlags{})
+	r.RegisterRoutes(&repository.Repository{}, &policy.Policies{}, &cache.Stores{}, middleware.NewMiddlewares(nil, nil, nil))
+
+	missing, err := r.VerifyDocument(doc)
+	if err != nil {
+		helper.LogFatal("failed to verify openapi document", "err", err)
+	}
+
+	if len(missing) > 0 {
+		for _, route := range missing {
+			fmt.Fprintf(os.Stderr, "route missing from openapi document: %s\n", route)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db"
+	"github.com/mithileshgupta12/velaris/internal/digest"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/invitation"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+	"github.com/mithileshgupta12/velaris/internal/mail"
+	"github.com/mithileshgupta12/velaris/internal/notification"
+	"github.com/mithileshgupta12/velaris/internal/outbox"
+	"github.com/mithileshgupta12/velaris/internal/route"
+	"github.com/mithileshgupta12/velaris/internal/webhook"
+)
+
+// worker runs the background work: queued and scheduled jobs, the outbox
+// relay and its consumers, notifications, digest and invitation emails and
+// webhook deliveries. Any number of workers can run side by side. The job
+// queue status is served on -status-addr:
+//
+//	velaris [global flags] worker [-concurrency=4] [-status-addr=localhost:8001]
+func worker(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("worker", flag.ExitOnError)
+	concurrency := flags.Int("concurrency", 4, "Number of jobs run at once")
+	statusAddr := flags.String("status-addr", "localhost:8001", "Address serving the job queue status; empty to disable")
+
+	if err := flags.Parse(args); err != nil {
+		helper.LogFatal("failed to parse flags", "err", err)
+	}
+
+	if cfg.App.Key == "" {
+		helper.LogFatal("app key is required to sign links", "flag", "-app-key")
+	}
+
+	repositories, policies, err := db.NewDB(&cfg.DB)
+	if err != nil {
+		helper.LogFatal("failed to connect to database", "err", err)
+	}
+
+	slog.Info("Connection to database successful")
+
+	cache, err := cache.NewRedisClient()
+	if err != nil {
+		helper.LogFatal("failed to connect to cache", "err", err)
+	}
+
+	stores := cache.InitStores()
+
+	slog.Info("Connection to cache successful")
+	defer cache.Close()
+
+	mailer, err := mail.NewMailer(&cfg.Mail)
+	if err != nil {
+		helper.LogFatal("failed to create mailer", "err", err)
+	}
+
+	queue := jobs.NewQueue(stores.JobQueue)
+	outbox.RegisterPruneJob(queue, repositories.OutboxRepository)
+	digest.NewDigester(
+		repositories.DigestRepository,
+		repositories.UserRepository,
+		policies.BoardPolicy,
+		mailer,
+		cfg.App.Key,
+		cfg.App.ApiUrl,
+		cfg.App.FrontendUrl,
+	).Register(queue)
+	invitation.NewSender(repositories.BoardInvitationRepository, mailer, cfg.App.Key, cfg.App.FrontendUrl).Register(queue)
+
+	// Running jobs are given the chance to finish on shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+
+	go outbox.NewRelay(repositories.OutboxRepository, stores.EventStream).Run(ctx)
+	go outbox.NewConsumer(stores.EventStream, webhook.ConsumerGroup, webhook.HandleEvent(repositories.WebhookRepository)).Run(ctx)
+	go outbox.NewConsumer(stores.EventStream, notification.ConsumerGroup, notification.HandleEvent(repositories.NotificationRepository, stores.NotificationBroker)).Run(ctx)
+	go webhook.NewDispatcher(repositories.WebhookRepository).Run(ctx)
+	go queue.RunScheduler(ctx, owner)
+
+	if *statusAddr != "" {
+		mux := chi.NewRouter()
+		route.JobRoutes(mux, queue)
+
+		server := &http.Server{Addr: *statusAddr, Handler: mux}
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+		go func() {
+			slog.Info("Job status server started", "address", *statusAddr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("failed to serve job status", "err", err)
+			}
+		}()
+	}
+
+	slog.Info("Worker started", "owner", owner, "concurrency", *concurrency)
+	queue.Work(ctx, *concurrency)
+	slog.Info("Worker stopped")
+}
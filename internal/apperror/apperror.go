@@ -0,0 +1,106 @@
+// Package apperror defines the errors the application reports to clients.
+// Each error has a Kind, which decides the HTTP status it is served with, and
+// a Code that clients can match on instead of the message.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an error by how the client should react to it.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindUnsupportedMediaType
+	KindUnprocessable
+	KindTooManyRequests
+	KindPayloadTooLarge
+)
+
+var kindStatuses = map[Kind]int{
+	KindInternal:             http.StatusInternalServerError,
+	KindInvalid:              http.StatusBadRequest,
+	KindUnauthenticated:      http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+	KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	KindUnprocessable:        http.StatusUnprocessableEntity,
+	KindTooManyRequests:      http.StatusTooManyRequests,
+	KindPayloadTooLarge:      http.StatusRequestEntityTooLarge,
+}
+
+// Status returns the HTTP status errors of kind are served with.
+func (k Kind) Status() int {
+	if status, ok := kindStatuses[k]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Error is an error with a stable, machine-readable code. Codes are part of
+// the API and must not change once released.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// From returns the application error err is or wraps. Any other error is
+// reported as ok == false.
+func From(err error) (appErr *Error, ok bool) {
+	ok = errors.As(err, &appErr)
+
+	return appErr, ok
+}
+
+// StatusCode returns a default code for errors only known by their HTTP
+// status, such as "not_found" for 404.
+func StatusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusPreconditionFailed:
+		return "precondition_failed"
+	case http.StatusRequestEntityTooLarge:
+		return "payload_too_large"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable_entity"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	default:
+		return "internal_error"
+	}
+}
@@ -0,0 +1,19 @@
+// Package backoff spaces out the retries of work that failed.
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Delay returns how long to wait after the given failed attempt, the first
+// being 1: base doubling with each attempt up to maxDelay, plus up to 10%
+// jitter so that work failing together does not retry together.
+func Delay(attempt int, base, maxDelay time.Duration) time.Duration {
+	delay := maxDelay
+	if attempt < 20 {
+		delay = min(base<<(attempt-1), maxDelay)
+	}
+
+	return delay + rand.N(delay/10+1)
+}
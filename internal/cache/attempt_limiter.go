@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AttemptLimiter counts failed attempts at guessing a secret, such as the
+// password of a share link, so that callers can refuse further attempts for a
+// while.
+type AttemptLimiter interface {
+	// Failures returns the failed attempts counted for key in its window.
+	Failures(ctx context.Context, key string) (int64, error)
+	// Fail counts a failed attempt for key. The count expires window after
+	// the first failure.
+	Fail(ctx context.Context, key string, window time.Duration) error
+}
+
+type attemptLimiter struct {
+	client *redis.Client
+}
+
+func NewAttemptLimiter(client *redis.Client) AttemptLimiter {
+	return &attemptLimiter{client}
+}
+
+func (al *attemptLimiter) Failures(ctx context.Context, key string) (int64, error) {
+	failures, err := al.client.Get(ctx, fmt.Sprintf("attempts:%s", key)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return failures, err
+}
+
+func (al *attemptLimiter) Fail(ctx context.Context, key string, window time.Duration) error {
+	key = fmt.Sprintf("attempts:%s", key)
+
+	_, err := al.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, window)
+
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// eventStreamKey is the Redis stream domain events are published to.
+const eventStreamKey = "events"
+
+// eventStreamMaxLen bounds the stream. Entries beyond it are trimmed, oldest
+// first, whether or not every group has read them.
+const eventStreamMaxLen = 100000
+
+// deadLetterStreamMaxLen bounds each dead-letter stream.
+const deadLetterStreamMaxLen = 10000
+
+// StreamMessage is an entry of the event stream.
+type StreamMessage struct {
+	// Id is the stream entry id, which acknowledges the message.
+	Id   string
+	Data string
+}
+
+type EventStream interface {
+	// Publish appends data to the stream, one entry each, in order.
+	Publish(ctx context.Context, data []string) error
+	// CreateGroup creates a consumer group that reads the entries published
+	// from now on. Creating an existing group does nothing.
+	CreateGroup(ctx context.Context, group string) error
+	// Pending returns up to count entries delivered to consumer but not yet
+	// acknowledged, oldest first.
+	Pending(ctx context.Context, group, consumer string, count int64) ([]StreamMessage, error)
+	// Read waits up to block for entries the group has not seen yet and
+	// delivers them to consumer.
+	Read(ctx context.Context, group, consumer string, count int64, block time.Duration) ([]StreamMessage, error)
+	// Claim hands to consumer the entries other consumers of the group left
+	// unacknowledged for at least minIdle, for instance because they died.
+	Claim(ctx context.Context, group, consumer string, minIdle time.Duration, count int64) error
+	// Ack marks entries as handled by the group.
+	Ack(ctx context.Context, group string, ids ...string) error
+	// DeadLetter appends an entry the group gave up on, with the reason, to
+	// the group's dead-letter stream, "events:dead:<group>", for inspection.
+	// The entry still has to be acknowledged.
+	DeadLetter(ctx context.Context, group string, message StreamMessage, reason string) error
+}
+
+type eventStream struct {
+	client *redis.Client
+}
+
+func NewEventStream(client *redis.Client) EventStream {
+	return &eventStream{client}
+}
+
+func (es *eventStream) Publish(ctx context.Context, data []string) error {
+	_, err := es.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, entry := range data {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: eventStreamKey,
+				MaxLen: eventStreamMaxLen,
+				Approx: true,
+				Values: map[string]any{"data": entry},
+			})
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (es *eventStream) CreateGroup(ctx context.Context, group string) error {
+	err := es.client.XGroupCreateMkStream(ctx, eventStreamKey, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+
+	return err
+}
+
+func (es *eventStream) Pending(ctx context.Context, group, consumer string, count int64) ([]StreamMessage, error) {
+	return es.read(ctx, group, consumer, "0", count, -1)
+}
+
+func (es *eventStream) Read(ctx context.Context, group, consumer string, count int64, block time.Duration) ([]StreamMessage, error) {
+	return es.read(ctx, group, consumer, ">", count, block)
+}
+
+func (es *eventStream) read(ctx context.Context, group, consumer, start string, count int64, block time.Duration) ([]StreamMessage, error) {
+	streams, err := es.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{eventStreamKey, start},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	messages := []StreamMessage{}
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			data, _ := message.Values["data"].(string)
+			messages = append(messages, StreamMessage{Id: message.ID, Data: data})
+		}
+	}
+
+	return messages, nil
+}
+
+func (es *eventStream) Claim(ctx context.Context, group, consumer string, minIdle time.Duration, count int64) error {
+	return es.client.XAutoClaimJustID(ctx, &redis.XAutoClaimArgs{
+		Stream:   eventStreamKey,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0-0",
+		Count:    count,
+	}).Err()
+}
+
+func (es *eventStream) Ack(ctx context.Context, group string, ids ...string) error {
+	return es.client.XAck(ctx, eventStreamKey, group, ids...).Err()
+}
+
+func (es *eventStream) DeadLetter(ctx context.Context, group string, message StreamMessage, reason string) error {
+	return es.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventStreamKey + ":dead:" + group,
+		MaxLen: deadLetterStreamMaxLen,
+		Approx: true,
+		Values: map[string]any{"id": message.Id, "data": message.Data, "reason": reason},
+	}).Err()
+}
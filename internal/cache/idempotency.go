@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotentResponse is a response saved to be replayed to retries of the
+// request that produced it.
+type IdempotentResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// IdempotencyRecord is what is stored for an idempotency key. Response is nil
+// while the first request with the key is still being handled.
+type IdempotencyRecord struct {
+	RequestHash string              `json:"request_hash"`
+	Response    *IdempotentResponse `json:"response,omitempty"`
+}
+
+type IdempotencyStore interface {
+	// Begin claims key for a request whose method, path and body hash to
+	// requestHash. If the key is already taken the existing record is
+	// returned and claimed is false. A claim expires after ttl unless it is
+	// completed.
+	Begin(ctx context.Context, key, requestHash string, ttl time.Duration) (record *IdempotencyRecord, claimed bool, err error)
+	// Complete saves the response of the request that claimed key.
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release gives up a claim so that the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type idempotencyStore struct {
+	client *redis.Client
+}
+
+func NewIdempotencyStore(client *redis.Client) IdempotencyStore {
+	return &idempotencyStore{client}
+}
+
+func (is *idempotencyStore) Begin(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	record := &IdempotencyRecord{RequestHash: requestHash}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, false, err
+	}
+
+	claimed, err := is.client.SetNX(ctx, fmt.Sprintf("idempotency:%s", key), data, ttl).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if claimed {
+		return record, true, nil
+	}
+
+	existing, err := is.client.Get(ctx, fmt.Sprintf("idempotency:%s", key)).Bytes()
+	if err != nil {
+		// The claim expired between SETNX and GET; let the client retry.
+		if errors.Is(err, redis.Nil) {
+			return record, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	if err := json.Unmarshal(existing, record); err != nil {
+		return nil, false, err
+	}
+
+	return record, false, nil
+}
+
+func (is *idempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return is.client.Set(ctx, fmt.Sprintf("idempotency:%s", key), data, ttl).Err()
+}
+
+func (is *idempotencyStore) Release(ctx context.Context, key string) error {
+	return is.client.Del(ctx, fmt.Sprintf("idempotency:%s", key)).Err()
+}
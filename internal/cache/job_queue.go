@@ -0,0 +1,366 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The job queue keeps every job that is waiting or scheduled in jobQueueKey
+// and every job being run in jobRunningKey, both scored by a time in unix
+// milliseconds: when the job is due, or when its visibility timeout expires.
+// The jobs themselves are stored in jobDataKey.
+const (
+	jobQueueKey    = "jobs:queue"
+	jobRunningKey  = "jobs:running"
+	jobDataKey     = "jobs:data"
+	jobAttemptsKey = "jobs:attempts"
+	jobFailedKey   = "jobs:failed"
+	jobStatsKey    = "jobs:stats"
+	jobLeaderKey   = "jobs:leader"
+
+	// jobFailedMaxLen bounds the list of failed jobs kept for inspection.
+	jobFailedMaxLen = 1000
+	// jobUniqueTTL is how long a unique key outlives a job that never
+	// finished, for instance because it was lost with its data.
+	jobUniqueTTL = 24 * time.Hour
+)
+
+// QueuedJob is a job as stored in the queue.
+type QueuedJob struct {
+	Id   string          `json:"id"`
+	Type string          `json:"type"`
+	Args json.RawMessage `json:"args"`
+	// UniqueKey, when set, is held from enqueueing until the job completes
+	// or fails; no other job can be enqueued with it meanwhile.
+	UniqueKey   string    `json:"unique_key,omitempty"`
+	MaxAttempts int       `json:"max_attempts"`
+	EnqueuedAt  time.Time `json:"enqueued_at"`
+	// Attempts counts the times the job was reserved, the current one
+	// included.
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	FailedAt  *time.Time `json:"failed_at,omitempty"`
+}
+
+// JobTypeCounts counts the outcomes of the attempts at jobs of a type.
+type JobTypeCounts struct {
+	Succeeded int64 `json:"succeeded"`
+	Retried   int64 `json:"retried"`
+	Failed    int64 `json:"failed"`
+}
+
+type JobQueueStatus struct {
+	// Ready jobs are due and waiting for a worker.
+	Ready int64 `json:"ready"`
+	// Scheduled jobs are delayed or waiting to be retried.
+	Scheduled int64 `json:"scheduled"`
+	Running   int64 `json:"running"`
+	Failed    int64 `json:"failed"`
+	// Leader is the process scheduling periodic jobs, if any.
+	Leader string                    `json:"leader"`
+	Types  map[string]*JobTypeCounts `json:"types"`
+	// RecentFailures are the last jobs that failed for good, newest first.
+	RecentFailures []*QueuedJob `json:"recent_failures"`
+}
+
+type JobQueue interface {
+	// Enqueue adds job to run at runAt. It returns false without adding it
+	// when another job holds its unique key.
+	Enqueue(ctx context.Context, job *QueuedJob, runAt time.Time) (bool, error)
+	// Reserve takes the next due job, or returns nil when there is none. The
+	// job is hidden from other workers for visibility, after which it is
+	// due again unless it was completed, retried or failed.
+	Reserve(ctx context.Context, visibility time.Duration) (*QueuedJob, error)
+	// Complete removes a job that succeeded.
+	Complete(ctx context.Context, job *QueuedJob) error
+	// Retry schedules a reserved job to run again at runAt.
+	Retry(ctx context.Context, job *QueuedJob, runAt time.Time) error
+	// Fail removes a job that will not be retried and keeps it in the list
+	// of failed jobs.
+	Fail(ctx context.Context, job *QueuedJob) error
+	// Once reports whether key is claimed for the first time within ttl.
+	Once(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Lead makes owner the leader for ttl, or extends its leadership, and
+	// reports whether it is the leader.
+	Lead(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+	Status(ctx context.Context, recentFailures int64) (*JobQueueStatus, error)
+}
+
+var (
+	enqueueJobScript = redis.NewScript(`
+		if ARGV[4] ~= "" and not redis.call("SET", ARGV[4], ARGV[1], "NX", "PX", ARGV[5]) then
+			return 0
+		end
+		redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+		redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+		return 1
+	`)
+
+	// reserveJobScript first returns the jobs whose visibility timeout
+	// expired to the queue.
+	reserveJobScript = redis.NewScript(`
+		local expired = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1])
+		for _, id in ipairs(expired) do
+			redis.call("ZREM", KEYS[2], id)
+			redis.call("ZADD", KEYS[1], ARGV[1], id)
+		end
+
+		local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)
+		if #ids == 0 then
+			return false
+		end
+
+		redis.call("ZREM", KEYS[1], ids[1])
+		redis.call("ZADD", KEYS[2], ARGV[2], ids[1])
+		local attempts = redis.call("HINCRBY", KEYS[4], ids[1], 1)
+
+		return {ids[1], redis.call("HGET", KEYS[3], ids[1]) or "", attempts}
+	`)
+
+	// retryJobScript leaves alone a job whose visibility timeout expired,
+	// since another worker may have reserved it since.
+	retryJobScript = redis.NewScript(`
+		if redis.call("ZREM", KEYS[2], ARGV[1]) == 0 then
+			return 0
+		end
+		redis.call("HSET", KEYS[3], ARGV[1], ARGV[2])
+		redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+		redis.call("HINCRBY", KEYS[4], ARGV[4], 1)
+		return 1
+	`)
+
+	leadScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			redis.call("PEXPIRE", KEYS[1], ARGV[2])
+			return 1
+		end
+		if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+			return 1
+		end
+		return 0
+	`)
+)
+
+type jobQueue struct {
+	client *redis.Client
+}
+
+func NewJobQueue(client *redis.Client) JobQueue {
+	return &jobQueue{client}
+}
+
+func (jq *jobQueue) Enqueue(ctx context.Context, job *QueuedJob, runAt time.Time) (bool, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return false, err
+	}
+
+	enqueued, err := enqueueJobScript.Run(
+		ctx,
+		jq.client,
+		[]string{jobQueueKey, jobDataKey},
+		job.Id, data, runAt.UnixMilli(), uniqueJobKey(job), jobUniqueTTL.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return enqueued == 1, nil
+}
+
+func (jq *jobQueue) Reserve(ctx context.Context, visibility time.Duration) (*QueuedJob, error) {
+	now := time.Now()
+
+	result, err := reserveJobScript.Run(
+		ctx,
+		jq.client,
+		[]string{jobQueueKey, jobRunningKey, jobDataKey, jobAttemptsKey},
+		now.UnixMilli(), now.Add(visibility).UnixMilli(),
+	).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	if len(result) != 3 {
+		return nil, fmt.Errorf("unexpected reserve result %v", result)
+	}
+
+	id, _ := result[0].(string)
+	data, _ := result[1].(string)
+	attempts, _ := result[2].(int64)
+
+	job := &QueuedJob{}
+	if err := json.Unmarshal([]byte(data), job); err != nil {
+		// The data is gone or malformed: keep what is known so that the
+		// job can be failed.
+		job = &QueuedJob{Id: id, LastError: fmt.Sprintf("undecodable job: %v", err)}
+	}
+	job.Attempts = int(attempts)
+
+	return job, nil
+}
+
+func (jq *jobQueue) Complete(ctx context.Context, job *QueuedJob) error {
+	_, err := jq.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		jq.remove(ctx, pipe, job)
+		pipe.HIncrBy(ctx, jobStatsKey, job.Type+".succeeded", 1)
+
+		return nil
+	})
+
+	return err
+}
+
+func (jq *jobQueue) Retry(ctx context.Context, job *QueuedJob, runAt time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return retryJobScript.Run(
+		ctx,
+		jq.client,
+		[]string{jobQueueKey, jobRunningKey, jobDataKey, jobStatsKey},
+		job.Id, data, runAt.UnixMilli(), job.Type+".retried",
+	).Err()
+}
+
+func (jq *jobQueue) Fail(ctx context.Context, job *QueuedJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = jq.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		jq.remove(ctx, pipe, job)
+		pipe.LPush(ctx, jobFailedKey, data)
+		pipe.LTrim(ctx, jobFailedKey, 0, jobFailedMaxLen-1)
+		pipe.HIncrBy(ctx, jobStatsKey, job.Type+".failed", 1)
+
+		return nil
+	})
+
+	return err
+}
+
+// remove deletes every trace of job from the queue, wherever it is.
+func (jq *jobQueue) remove(ctx context.Context, pipe redis.Pipeliner, job *QueuedJob) {
+	pipe.ZRem(ctx, jobQueueKey, job.Id)
+	pipe.ZRem(ctx, jobRunningKey, job.Id)
+	pipe.HDel(ctx, jobDataKey, job.Id)
+	pipe.HDel(ctx, jobAttemptsKey, job.Id)
+
+	if key := uniqueJobKey(job); key != "" {
+		pipe.Del(ctx, key)
+	}
+}
+
+func (jq *jobQueue) Once(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return jq.client.SetNX(ctx, fmt.Sprintf("jobs:once:%s", key), 1, ttl).Result()
+}
+
+func (jq *jobQueue) Lead(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	leading, err := leadScript.Run(ctx, jq.client, []string{jobLeaderKey}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return leading == 1, nil
+}
+
+func (jq *jobQueue) Status(ctx context.Context, recentFailures int64) (*JobQueueStatus, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	var (
+		ready, scheduled, running, failed *redis.IntCmd
+		leader                            *redis.StringCmd
+		stats                             *redis.MapStringStringCmd
+		recent                            *redis.StringSliceCmd
+	)
+
+	_, err := jq.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		ready = pipe.ZCount(ctx, jobQueueKey, "-inf", now)
+		scheduled = pipe.ZCount(ctx, jobQueueKey, "("+now, "+inf")
+		running = pipe.ZCard(ctx, jobRunningKey)
+		failed = pipe.LLen(ctx, jobFailedKey)
+		leader = pipe.Get(ctx, jobLeaderKey)
+		stats = pipe.HGetAll(ctx, jobStatsKey)
+		recent = pipe.LRange(ctx, jobFailedKey, 0, recentFailures-1)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	status := &JobQueueStatus{
+		Ready:          ready.Val(),
+		Scheduled:      scheduled.Val(),
+		Running:        running.Val(),
+		Failed:         failed.Val(),
+		Leader:         leader.Val(),
+		Types:          map[string]*JobTypeCounts{},
+		RecentFailures: []*QueuedJob{},
+	}
+
+	for field, value := range stats.Val() {
+		jobType, outcome, ok := splitJobStatsField(field)
+		if !ok {
+			continue
+		}
+
+		counts := status.Types[jobType]
+		if counts == nil {
+			counts = &JobTypeCounts{}
+			status.Types[jobType] = counts
+		}
+
+		count, _ := strconv.ParseInt(value, 10, 64)
+		switch outcome {
+		case "succeeded":
+			counts.Succeeded = count
+		case "retried":
+			counts.Retried = count
+		case "failed":
+			counts.Failed = count
+		}
+	}
+
+	for _, data := range recent.Val() {
+		job := &QueuedJob{}
+		if err := json.Unmarshal([]byte(data), job); err == nil {
+			status.RecentFailures = append(status.RecentFailures, job)
+		}
+	}
+
+	return status, nil
+}
+
+// splitJobStatsField splits a field of the stats hash, "<type>.<outcome>",
+// on its last dot since job types contain dots themselves.
+func splitJobStatsField(field string) (jobType, outcome string, ok bool) {
+	i := strings.LastIndex(field, ".")
+	if i < 0 {
+		return "", "", false
+	}
+
+	return field[:i], field[i+1:], true
+}
+
+func uniqueJobKey(job *QueuedJob) string {
+	if job.UniqueKey == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("jobs:unique:%s:%s", job.Type, job.UniqueKey)
+}
@@ -0,0 +1,23 @@
+package cache
+
+import "testing"
+
+func TestSplitJobStatsField(t *testing.T) {
+	tests := []struct {
+		field, jobType, outcome string
+		ok                      bool
+	}{
+		{"digests.send.succeeded", "digests.send", "succeeded", true},
+		{"outbox.prune.failed", "outbox.prune", "failed", true},
+		{"cleanup.retried", "cleanup", "retried", true},
+		{"malformed", "", "", false},
+	}
+
+	for _, tt := range tests {
+		jobType, outcome, ok := splitJobStatsField(tt.field)
+		if jobType != tt.jobType || outcome != tt.outcome || ok != tt.ok {
+			t.Errorf("splitJobStatsField(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.field, jobType, outcome, ok, tt.jobType, tt.outcome, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NotificationSubscription receives the notifications published to a user
+// while it is open.
+type NotificationSubscription interface {
+	Messages() <-chan string
+	Close() error
+}
+
+// NotificationBroker passes notifications from the process that creates them
+// to the processes holding the user's open connections. Notifications
+// published while nobody is subscribed are not kept.
+type NotificationBroker interface {
+	Publish(ctx context.Context, userId int64, data string) error
+	Subscribe(ctx context.Context, userId int64) (NotificationSubscription, error)
+}
+
+type notificationBroker struct {
+	client *redis.Client
+}
+
+func NewNotificationBroker(client *redis.Client) NotificationBroker {
+	return &notificationBroker{client}
+}
+
+func (nb *notificationBroker) Publish(ctx context.Context, userId int64, data string) error {
+	return nb.client.Publish(ctx, notificationChannel(userId), data).Err()
+}
+
+func (nb *notificationBroker) Subscribe(ctx context.Context, userId int64) (NotificationSubscription, error) {
+	pubsub := nb.client.Subscribe(ctx, notificationChannel(userId))
+
+	// Wait for the subscription to be confirmed so that nothing published
+	// after Subscribe returns is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	subscription := &notificationSubscription{
+		pubsub:   pubsub,
+		messages: make(chan string),
+		closed:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(subscription.messages)
+
+		for message := range pubsub.Channel() {
+			select {
+			case subscription.messages <- message.Payload:
+			case <-subscription.closed:
+				return
+			}
+		}
+	}()
+
+	return subscription, nil
+}
+
+type notificationSubscription struct {
+	pubsub    *redis.PubSub
+	messages  chan string
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (ns *notificationSubscription) Messages() <-chan string {
+	return ns.messages
+}
+
+func (ns *notificationSubscription) Close() error {
+	ns.closeOnce.Do(func() {
+		close(ns.closed)
+	})
+
+	return ns.pubsub.Close()
+}
+
+func notificationChannel(userId int64) string {
+	return fmt.Sprintf("notifications:%d", userId)
+}
@@ -12,7 +12,12 @@ type RedisClient struct {
 }
 
 type Stores struct {
-	SessionStore SessionStore
+	SessionStore       SessionStore
+	IdempotencyStore   IdempotencyStore
+	EventStream        EventStream
+	JobQueue           JobQueue
+	NotificationBroker NotificationBroker
+	AttemptLimiter     AttemptLimiter
 }
 
 func NewRedisClient() (*RedisClient, error) {
@@ -33,8 +38,13 @@ func NewRedisClient() (*RedisClient, error) {
 
 func (rc *RedisClient) InitStores() *Stores {
 	sessionStore := NewSessionStore(rc.client)
+	idempotencyStore := NewIdempotencyStore(rc.client)
+	eventStream := NewEventStream(rc.client)
+	jobQueue := NewJobQueue(rc.client)
+	notificationBroker := NewNotificationBroker(rc.client)
+	attemptLimiter := NewAttemptLimiter(rc.client)
 
-	return &Stores{sessionStore}
+	return &Stores{sessionStore, idempotencyStore, eventStream, jobQueue, notificationBroker, attemptLimiter}
 }
 
 func (rc *RedisClient) Close() {
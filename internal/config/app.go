@@ -5,11 +5,18 @@ import "flag"
 type AppFlags struct {
 	Port        int
 	FrontendUrl string
+	// ApiUrl is the public URL of the API, used for links in emails.
+	ApiUrl string
+	// Key signs the tokens handed out in links, such as unsubscribe links.
+	// Every process must share it.
+	Key string
 }
 
 func (af *AppFlags) Load() *AppFlags {
 	flag.IntVar(&af.Port, "app-port", 8000, "Port number for the application server")
 	flag.StringVar(&af.FrontendUrl, "frontend-url", "http://localhost:8000", "Frontend URL for CORS and redirects")
+	flag.StringVar(&af.ApiUrl, "api-url", "http://localhost:8000", "Public URL of the API for links in emails")
+	flag.StringVar(&af.Key, "app-key", "", "Secret key signing links; must be set in production")
 
 	return af
 }
@@ -3,14 +3,16 @@ package config
 import "flag"
 
 type Config struct {
-	DB  DBFlags
-	App AppFlags
+	DB   DBFlags
+	App  AppFlags
+	Mail MailFlags
 }
 
 func NewConfig() *Config {
 	c := &Config{}
 	c.DB.Load()
 	c.App.Load()
+	c.Mail.Load()
 
 	flag.Parse()
 
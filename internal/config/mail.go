@@ -0,0 +1,26 @@
+package config
+
+import "flag"
+
+type MailFlags struct {
+	// Driver is "smtp" to send mail or "file" to write it to Dir.
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	Dir          string
+}
+
+func (mf *MailFlags) Load() *MailFlags {
+	flag.StringVar(&mf.Driver, "mail-driver", "file", "Mail driver: smtp, or file to write messages to -mail-dir")
+	flag.StringVar(&mf.From, "mail-from", "Velaris <no-reply@localhost>", "Sender of outgoing mail")
+	flag.StringVar(&mf.SMTPHost, "smtp-host", "localhost", "SMTP server host")
+	flag.IntVar(&mf.SMTPPort, "smtp-port", 587, "SMTP server port")
+	flag.StringVar(&mf.SMTPUsername, "smtp-username", "", "SMTP username; empty to send without authentication")
+	flag.StringVar(&mf.SMTPPassword, "smtp-password", "", "SMTP password")
+	flag.StringVar(&mf.Dir, "mail-dir", "./target/mail", "Directory the file mail driver writes messages to")
+
+	return mf
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE lists DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE boards DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
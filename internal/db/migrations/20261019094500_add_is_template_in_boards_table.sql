@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS IDX_boards_user_id_is_template ON boards (user_id) WHERE is_template;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS IDX_boards_user_id_is_template;
+
+ALTER TABLE boards DROP COLUMN IF EXISTS is_template;
+-- +goose StatementEnd
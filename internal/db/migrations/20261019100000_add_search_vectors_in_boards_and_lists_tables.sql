@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS IDX_boards_search_vector ON boards USING GIN (search_vector);
+
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(name, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS IDX_lists_search_vector ON lists USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS IDX_lists_search_vector;
+ALTER TABLE lists DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS IDX_boards_search_vector;
+ALTER TABLE boards DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
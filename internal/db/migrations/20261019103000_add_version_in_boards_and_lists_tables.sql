@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE lists DROP COLUMN IF EXISTS version;
+ALTER TABLE boards DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(255) NOT NULL,
+    board_id BIGINT NOT NULL,
+    actor_id BIGINT,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS IDX_outbox_unpublished ON outbox (id) WHERE published_at IS NULL;
+
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS event_id BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS UQE_webhook_deliveries_webhook_id_event_id ON webhook_deliveries (webhook_id, event_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS UQE_webhook_deliveries_webhook_id_event_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS event_id;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
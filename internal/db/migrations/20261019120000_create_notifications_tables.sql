@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    board_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event VARCHAR(255) NOT NULL,
+    actor_id BIGINT,
+    data TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS UQE_notifications_user_id_event_id ON notifications (user_id, event_id);
+CREATE INDEX IF NOT EXISTS IDX_notifications_user_id_created_at ON notifications (user_id, created_at);
+CREATE INDEX IF NOT EXISTS IDX_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, event)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS watchers (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    board_id BIGINT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    list_id BIGINT REFERENCES lists(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS UQE_watchers_user_id_board_id ON watchers (user_id, board_id) WHERE list_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS UQE_watchers_user_id_list_id ON watchers (user_id, list_id) WHERE list_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS IDX_watchers_board_id ON watchers (board_id);
+CREATE INDEX IF NOT EXISTS IDX_watchers_list_id ON watchers (list_id) WHERE list_id IS NOT NULL;
+
+-- Owners were notified of everything on their boards until now.
+INSERT INTO watchers (user_id, board_id, created_at)
+SELECT user_id, id, created_at FROM boards;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS watchers;
+-- +goose StatementEnd
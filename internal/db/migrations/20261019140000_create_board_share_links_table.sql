@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS board_share_links (
+    id BIGSERIAL PRIMARY KEY,
+    board_id BIGINT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    password_hash VARCHAR(255),
+    expires_at TIMESTAMPTZ,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS UQE_board_share_links_token_hash ON board_share_links (token_hash);
+CREATE INDEX IF NOT EXISTS IDX_board_share_links_board_id ON board_share_links (board_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS board_share_links;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE board_invitations ADD COLUMN IF NOT EXISTS guest BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE board_invitations DROP COLUMN IF EXISTS guest;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_guest;
+-- +goose StatementEnd
@@ -3,14 +3,18 @@ package models
 import "time"
 
 type Board struct {
-	Id          int64     `json:"id"`
-	Name        string    `xorm:"NOT NULL" json:"name"`
-	Description *string   `xorm:"TEXT" json:"description"`
-	UserId      int64     `xorm:"INDEX NOT NULL" json:"user_id"`
-	User        *User     `xorm:"-" json:"user"`
-	Lists       []*List   `xorm:"-" json:"lists"`
-	CreatedAt   time.Time `xorm:"NOT NULL created" json:"created_at"`
-	UpdatedAt   time.Time `xorm:"NOT NULL updated" json:"updated_at"`
+	Id          int64         `json:"id"`
+	Name        string        `xorm:"NOT NULL" json:"name"`
+	Description *string       `xorm:"TEXT" json:"description"`
+	UserId      int64         `xorm:"INDEX NOT NULL" json:"user_id"`
+	User        *PublicUser   `xorm:"-" json:"user"`
+	Lists       []*List       `xorm:"-" json:"lists"`
+	Watchers    []*PublicUser `xorm:"-" json:"watchers"`
+	IsTemplate  bool          `xorm:"NOT NULL DEFAULT false" json:"is_template"`
+	ArchivedAt  *time.Time    `xorm:"TIMESTAMPZ" json:"archived_at"`
+	Version     int           `xorm:"NOT NULL DEFAULT 1 version" json:"version"`
+	CreatedAt   time.Time     `xorm:"NOT NULL created" json:"created_at"`
+	UpdatedAt   time.Time     `xorm:"NOT NULL updated" json:"updated_at"`
 }
 
 func (b *Board) TableName() string {
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Roles of the users with access to a board. The owner is the user who
+// created it; the other roles are granted by invitation.
+const (
+	BoardRoleOwner  = "owner"
+	BoardRoleEditor = "editor"
+	BoardRoleViewer = "viewer"
+)
+
+// BoardInvitationRoles are the roles an invitation can grant.
+var BoardInvitationRoles = []string{BoardRoleEditor, BoardRoleViewer}
+
+type BoardMember struct {
+	BoardId   int64     `xorm:"pk" json:"board_id"`
+	UserId    int64     `xorm:"pk" json:"user_id"`
+	User      *User     `xorm:"-" json:"user"`
+	Role      string    `xorm:"NOT NULL" json:"role"`
+	CreatedAt time.Time `xorm:"NOT NULL created" json:"created_at"`
+}
+
+func (bm *BoardMember) TableName() string {
+	return "board_members"
+}
+
+// BoardInvitation is an invitation to a board not accepted yet. Accepting it
+// turns it into a BoardMember. Registering through a Guest invitation creates
+// a guest account; it does not change existing accounts.
+type BoardInvitation struct {
+	Id        int64      `json:"id"`
+	BoardId   int64      `xorm:"NOT NULL" json:"board_id"`
+	Email     string     `xorm:"NOT NULL" json:"email"`
+	Role      string     `xorm:"NOT NULL" json:"role"`
+	Guest     bool       `xorm:"NOT NULL DEFAULT false" json:"guest"`
+	InvitedBy *int64     `json:"invited_by"`
+	ExpiresAt time.Time  `xorm:"NOT NULL" json:"expires_at"`
+	SentAt    *time.Time `json:"sent_at"`
+	CreatedAt time.Time  `xorm:"NOT NULL created" json:"created_at"`
+	UpdatedAt time.Time  `xorm:"NOT NULL updated" json:"updated_at"`
+}
+
+func (bi *BoardInvitation) TableName() string {
+	return "board_invitations"
+}
+
+// UnsentBoardInvitation is an invitation waiting for its email, with what
+// the email says about it.
+type UnsentBoardInvitation struct {
+	BoardInvitation `xorm:"extends"`
+	BoardName       string
+	InviterName     *string
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// BoardShareLink lets anyone holding its token view the board without an
+// account.
+type BoardShareLink struct {
+	Id      int64 `json:"id"`
+	BoardId int64 `xorm:"NOT NULL" json:"board_id"`
+	// Token is only known when the link is created. The link is looked up
+	// by TokenHash afterwards.
+	Token     string `xorm:"-" json:"token,omitempty"`
+	TokenHash string `xorm:"NOT NULL" json:"-"`
+	// PasswordHash is nil for links that need no password.
+	PasswordHash *string    `json:"-"`
+	HasPassword  bool       `xorm:"-" json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	CreatedBy    *int64     `json:"created_by"`
+	CreatedAt    time.Time  `xorm:"NOT NULL created" json:"created_at"`
+}
+
+func (bsl *BoardShareLink) TableName() string {
+	return "board_share_links"
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Digest frequencies. A digest batches the notifications a user has not read
+// into one email.
+const (
+	DigestImmediate = "immediate"
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+	DigestOff       = "off"
+
+	// DefaultDigestFrequency applies to users who never chose one.
+	DefaultDigestFrequency = DigestDaily
+)
+
+var DigestFrequencies = []string{DigestImmediate, DigestHourly, DigestDaily, DigestOff}
+
+type DigestSetting struct {
+	UserId    int64  `xorm:"pk" json:"-"`
+	Frequency string `xorm:"NOT NULL" json:"frequency"`
+	// LastNotificationId is the newest notification sent in a digest; the
+	// next digest starts after it.
+	LastNotificationId int64      `xorm:"NOT NULL DEFAULT 0" json:"-"`
+	SentAt             *time.Time `xorm:"TIMESTAMPZ" json:"sent_at"`
+	UpdatedAt          time.Time  `xorm:"NOT NULL updated" json:"updated_at"`
+}
+
+func (ds *DigestSetting) TableName() string {
+	return "digest_settings"
+}
+
+// DigestNotification is a notification with the names a digest shows.
+type DigestNotification struct {
+	Notification `xorm:"extends"`
+	// BoardName and ActorName are nil when the board or the actor no longer
+	// exists.
+	BoardName *string
+	ActorName *string
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Notification tells a user about an event on a board they are interested
+// in.
+type Notification struct {
+	Id      int64 `json:"id"`
+	UserId  int64 `xorm:"NOT NULL" json:"-"`
+	BoardId int64 `xorm:"NOT NULL" json:"board_id"`
+	// EventId is the outbox id of the event.
+	EventId int64  `xorm:"NOT NULL" json:"-"`
+	Event   string `xorm:"NOT NULL" json:"event"`
+	ActorId *int64 `json:"actor_id"`
+	// Data is the board or list the event is about, as it was after the
+	// event.
+	Data      map[string]any `xorm:"TEXT NOT NULL" json:"data"`
+	ReadAt    *time.Time     `xorm:"TIMESTAMPZ" json:"read_at"`
+	CreatedAt time.Time      `xorm:"NOT NULL created" json:"created_at"`
+}
+
+func (n *Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference turns the notifications of an event on or off for a
+// user. Events without a preference notify.
+type NotificationPreference struct {
+	UserId  int64  `xorm:"pk" json:"-"`
+	Event   string `xorm:"pk" json:"event"`
+	Enabled bool   `xorm:"NOT NULL" json:"enabled"`
+}
+
+func (np *NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
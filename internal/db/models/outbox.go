@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event saved in the transaction of the change it
+// describes, waiting to be published.
+type OutboxEvent struct {
+	Id      int64  `json:"id"`
+	Event   string `xorm:"NOT NULL" json:"event"`
+	BoardId int64  `xorm:"NOT NULL" json:"board_id"`
+	ActorId *int64 `json:"actor_id"`
+	// Payload is the JSON of the board or list the event is about.
+	Payload     string     `xorm:"TEXT NOT NULL" json:"payload"`
+	CreatedAt   time.Time  `xorm:"NOT NULL created" json:"created_at"`
+	PublishedAt *time.Time `xorm:"TIMESTAMPZ" json:"published_at"`
+}
+
+func (oe *OutboxEvent) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,12 @@
+package models
+
+// SearchHit is a board or list matching a search query. Headline is the
+// matched text with the matching words wrapped in <mark> tags.
+type SearchHit struct {
+	Type     string  `json:"type"`
+	Id       int64   `json:"id"`
+	BoardId  int64   `json:"board_id"`
+	Name     string  `json:"name"`
+	Headline string  `json:"headline"`
+	Rank     float64 `json:"rank"`
+}
@@ -0,0 +1,70 @@
+package models
+
+// Template is a reusable board structure. User templates are boards marked
+// with IsTemplate, built-in templates ship with Velaris and are identified by
+// a slug instead of a board id.
+type Template struct {
+	Id          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Builtin     bool            `json:"builtin"`
+	Lists       []*TemplateList `json:"lists"`
+}
+
+type TemplateList struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+var BuiltinTemplates = []*Template{
+	{
+		Id:          "kanban",
+		Name:        "Kanban",
+		Description: stringPtr("Track work as it moves from the backlog to done."),
+		Builtin:     true,
+		Lists: []*TemplateList{
+			{Name: "Backlog", Position: 1},
+			{Name: "In progress", Position: 2},
+			{Name: "Review", Position: 3},
+			{Name: "Done", Position: 4},
+		},
+	},
+	{
+		Id:          "todo",
+		Name:        "To-do",
+		Description: stringPtr("A simple to-do board."),
+		Builtin:     true,
+		Lists: []*TemplateList{
+			{Name: "To do", Position: 1},
+			{Name: "Doing", Position: 2},
+			{Name: "Done", Position: 3},
+		},
+	},
+	{
+		Id:          "retrospective",
+		Name:        "Retrospective",
+		Description: stringPtr("Collect feedback at the end of a sprint."),
+		Builtin:     true,
+		Lists: []*TemplateList{
+			{Name: "Went well", Position: 1},
+			{Name: "To improve", Position: 2},
+			{Name: "Action items", Position: 3},
+		},
+	},
+}
+
+// GetBuiltinTemplate returns the built-in template with the given id, or nil
+// when there is none.
+func GetBuiltinTemplate(id string) *Template {
+	for _, template := range BuiltinTemplates {
+		if template.Id == id {
+			return template
+		}
+	}
+
+	return nil
+}
@@ -2,16 +2,31 @@ package models
 
 import "time"
 
+// User is an account. Guests, with IsGuest set, are created through a guest
+// invitation and only see the boards they are invited to.
 type User struct {
-	Id        int64
-	Name      string    `xorm:"NOT NULL"`
-	Email     string    `xorm:"NOT NULL UNIQUE"`
+	Id        int64     `json:"id"`
+	Name      string    `xorm:"NOT NULL" json:"name"`
+	Email     string    `xorm:"NOT NULL UNIQUE" json:"email"`
 	Password  string    `xorm:"NOT NULL" json:"-"`
-	Boards    []*Board  `xorm:"-"`
-	CreatedAt time.Time `xorm:"NOT NULL created"`
-	UpdatedAt time.Time `xorm:"NOT NULL updated"`
+	IsGuest   bool      `xorm:"NOT NULL DEFAULT false" json:"is_guest"`
+	Boards    []*Board  `xorm:"-" json:"boards"`
+	CreatedAt time.Time `xorm:"NOT NULL created" json:"created_at"`
+	UpdatedAt time.Time `xorm:"NOT NULL updated" json:"updated_at"`
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
+
+// PublicUser is what other users see of a user when it comes along with a
+// board, without the email.
+type PublicUser struct {
+	Id      int64  `json:"id"`
+	Name    string `json:"name"`
+	IsGuest bool   `json:"is_guest"`
+}
+
+func (pu *PublicUser) TableName() string {
+	return "users"
+}
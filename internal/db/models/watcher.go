@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Watcher is a user following a board, or a single list of it when ListId is
+// set.
+type Watcher struct {
+	Id        int64     `json:"-"`
+	UserId    int64     `xorm:"NOT NULL" json:"user_id"`
+	BoardId   int64     `xorm:"NOT NULL" json:"board_id"`
+	ListId    *int64    `json:"list_id"`
+	CreatedAt time.Time `xorm:"NOT NULL created" json:"created_at"`
+}
+
+func (w *Watcher) TableName() string {
+	return "watchers"
+}
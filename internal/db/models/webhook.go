@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	Id      int64  `json:"id"`
+	BoardId int64  `xorm:"INDEX NOT NULL" json:"board_id"`
+	Url     string `xorm:"TEXT NOT NULL" json:"url"`
+	// Secret signs deliveries and is never sent back to clients.
+	Secret string `xorm:"NOT NULL" json:"-"`
+	// Events are the events delivered to the webhook. Empty means all.
+	Events    []string  `xorm:"TEXT NOT NULL" json:"events"`
+	CreatedAt time.Time `xorm:"NOT NULL created" json:"created_at"`
+	UpdatedAt time.Time `xorm:"NOT NULL updated" json:"updated_at"`
+}
+
+func (w *Webhook) TableName() string {
+	return "webhooks"
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryDead marks deliveries that ran out of attempts.
+	WebhookDeliveryDead = "dead"
+)
+
+type WebhookDelivery struct {
+	Id        int64    `json:"id"`
+	WebhookId int64    `xorm:"INDEX NOT NULL" json:"webhook_id"`
+	Webhook   *Webhook `xorm:"-" json:"-"`
+	Event     string   `xorm:"NOT NULL" json:"event"`
+	// EventId is the outbox id of the event, unique per webhook so that a
+	// redelivered event is not queued twice.
+	EventId *int64 `json:"event_id"`
+	// Payload is the JSON body sent to the webhook.
+	Payload      string `xorm:"TEXT NOT NULL" json:"payload"`
+	Status       string `xorm:"NOT NULL DEFAULT 'pending'" json:"status"`
+	AttemptCount int    `xorm:"NOT NULL DEFAULT 0" json:"attempt_count"`
+	// NextAttemptAt is when the delivery is next tried, nil once it
+	// succeeded or is dead.
+	NextAttemptAt  *time.Time                `xorm:"TIMESTAMPZ" json:"next_attempt_at"`
+	LastStatusCode *int                      `json:"last_status_code"`
+	DeliveredAt    *time.Time                `xorm:"TIMESTAMPZ" json:"delivered_at"`
+	Attempts       []*WebhookDeliveryAttempt `xorm:"-" json:"attempts"`
+	CreatedAt      time.Time                 `xorm:"NOT NULL created" json:"created_at"`
+	UpdatedAt      time.Time                 `xorm:"NOT NULL updated" json:"updated_at"`
+}
+
+func (wd *WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+type WebhookDeliveryAttempt struct {
+	Id         int64 `json:"id"`
+	DeliveryId int64 `xorm:"INDEX NOT NULL" json:"delivery_id"`
+	// StatusCode is nil when no response was received.
+	StatusCode *int      `json:"status_code"`
+	Error      *string   `xorm:"TEXT" json:"error"`
+	DurationMs int       `xorm:"NOT NULL" json:"duration_ms"`
+	CreatedAt  time.Time `xorm:"NOT NULL created" json:"created_at"`
+}
+
+func (wda *WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
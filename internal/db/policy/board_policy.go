@@ -1,42 +1,138 @@
 package policy
 
 import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
-	"xorm.io/xorm"
 )
 
 type boardPolicy struct {
-	engine *xorm.Engine
+	engine repository.DB
 }
 
-func NewBoardPolicy(engine *xorm.Engine) Policy {
+func NewBoardPolicy(engine repository.DB) BoardPolicy {
 	return &boardPolicy{engine}
 }
 
-func (bp *boardPolicy) userOwnsBoard(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	exists, err := bp.engine.
-		Where("id = ? AND user_id = ?", id, ctxUser.ID).
-		Exist()
-	if err != nil {
-		return false, err
+// sharedBoardId returns the board of the share link of the user, or 0 when
+// the link does not exist, expired or has a password SharePassword is not.
+func (bp *boardPolicy) sharedBoardId(ctxUser middleware.CtxUser) (int64, error) {
+	link := &models.BoardShareLink{}
+
+	has, err := bp.engine.
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", helper.HashToken(ctxUser.ShareToken), time.Now()).
+		Get(link)
+	if err != nil || !has {
+		return 0, err
 	}
-	if !exists {
-		return false, nil
+
+	if link.PasswordHash != nil {
+		ok, err := helper.VerifyPassword(ctxUser.SharePassword, *link.PasswordHash)
+		if err != nil || !ok {
+			return 0, nil
+		}
 	}
 
-	return true, nil
+	return link.BoardId, nil
+}
+
+// boardRole returns the role of the user on the board, or "" when they have
+// no access to it. A share link of the board makes its holder a viewer.
+func (bp *boardPolicy) boardRole(ctxUser middleware.CtxUser, id int64) (string, error) {
+	if ctxUser.ShareToken != "" {
+		boardId, err := bp.sharedBoardId(ctxUser)
+		if err != nil || boardId != id {
+			return "", err
+		}
+
+		return models.BoardRoleViewer, nil
+	}
+
+	var role string
+
+	_, err := bp.engine.SQL(`
+		SELECT ?::VARCHAR FROM boards WHERE id = ? AND user_id = ?
+		UNION ALL
+		SELECT role FROM board_members WHERE board_id = ? AND user_id = ?`,
+		models.BoardRoleOwner, id, ctxUser.ID,
+		id, ctxUser.ID,
+	).Get(&role)
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
 }
 
 func (bp *boardPolicy) CanView(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return bp.userOwnsBoard(ctxUser, id)
+	role, err := bp.boardRole(ctxUser, id)
+	if err != nil {
+		return false, err
+	}
+
+	return role != "", nil
 }
+
+// CanCreate checks if the user can create boards, which guests can't. The id
+// is unused.
 func (bp *boardPolicy) CanCreate(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return true, nil
+	return !ctxUser.IsGuest, nil
 }
+
 func (bp *boardPolicy) CanUpdate(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return bp.userOwnsBoard(ctxUser, id)
+	role, err := bp.boardRole(ctxUser, id)
+	if err != nil {
+		return false, err
+	}
+
+	return role == models.BoardRoleOwner || role == models.BoardRoleEditor, nil
 }
 
 func (bp *boardPolicy) CanDelete(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return bp.userOwnsBoard(ctxUser, id)
+	role, err := bp.boardRole(ctxUser, id)
+	if err != nil {
+		return false, err
+	}
+
+	return role == models.BoardRoleOwner, nil
+}
+
+func (bp *boardPolicy) CanManageMembers(ctxUser middleware.CtxUser, id int64) (bool, error) {
+	role, err := bp.boardRole(ctxUser, id)
+	if err != nil {
+		return false, err
+	}
+
+	return role == models.BoardRoleOwner, nil
+}
+
+func (bp *boardPolicy) CanViewMembers(ctxUser middleware.CtxUser, id int64) (bool, error) {
+	if ctxUser.IsGuest {
+		return false, nil
+	}
+
+	return bp.CanView(ctxUser, id)
+}
+
+func (bp *boardPolicy) ViewScope(ctxUser middleware.CtxUser, boardIdColumn string) (string, []any) {
+	// Passwords can't be checked in SQL, so the link is resolved first. On
+	// failure the scope matches nothing.
+	if ctxUser.ShareToken != "" {
+		boardId, err := bp.sharedBoardId(ctxUser)
+		if err != nil {
+			slog.Error("failed to resolve board share link", "err", err)
+		}
+
+		return fmt.Sprintf("%s = ?", boardIdColumn), []any{boardId}
+	}
+
+	return fmt.Sprintf(
+		"%s IN (SELECT id FROM boards WHERE user_id = ? UNION ALL SELECT board_id FROM board_members WHERE user_id = ?)",
+		boardIdColumn,
+	), []any{ctxUser.ID, ctxUser.ID}
 }
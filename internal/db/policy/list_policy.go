@@ -1,37 +1,58 @@
 package policy
 
 import (
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
-	"xorm.io/xorm"
 )
 
 type listPolicy struct {
-	engine *xorm.Engine
+	engine repository.DB
 }
 
-func NewListPolicy(engine *xorm.Engine) Policy {
+func NewListPolicy(engine repository.DB) Policy {
 	return &listPolicy{engine}
 }
 
-func (lp *listPolicy) userOwnsList(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	exists, err := lp.engine.
-		Alias("l").
-		Where("l.id = ?", id).
-		Join("INNER", "boards b", "b.id = l.board_id").
-		Where("b.user_id = ?", ctxUser.ID).
-		Exist()
+// listRole returns the role of the user on the board of the list, or "" when
+// they have no access to it.
+func (lp *listPolicy) listRole(ctxUser middleware.CtxUser, id int64) (string, error) {
+	var role string
+
+	_, err := lp.engine.SQL(`
+		SELECT ?::VARCHAR FROM lists l
+		INNER JOIN boards b ON b.id = l.board_id
+		WHERE l.id = ? AND b.user_id = ?
+		UNION ALL
+		SELECT m.role FROM lists l
+		INNER JOIN board_members m ON m.board_id = l.board_id
+		WHERE l.id = ? AND m.user_id = ?`,
+		models.BoardRoleOwner, id, ctxUser.ID,
+		id, ctxUser.ID,
+	).Get(&role)
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	if !exists {
-		return false, nil
+
+	return role, nil
+}
+
+func (lp *listPolicy) canWrite(ctxUser middleware.CtxUser, id int64) (bool, error) {
+	role, err := lp.listRole(ctxUser, id)
+	if err != nil {
+		return false, err
 	}
 
-	return true, nil
+	return role == models.BoardRoleOwner || role == models.BoardRoleEditor, nil
 }
 
 func (lp *listPolicy) CanView(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return lp.userOwnsList(ctxUser, id)
+	role, err := lp.listRole(ctxUser, id)
+	if err != nil {
+		return false, err
+	}
+
+	return role != "", nil
 }
 
 func (lp *listPolicy) CanCreate(ctxUser middleware.CtxUser, id int64) (bool, error) {
@@ -39,9 +60,9 @@ func (lp *listPolicy) CanCreate(ctxUser middleware.CtxUser, id int64) (bool, err
 }
 
 func (lp *listPolicy) CanUpdate(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return lp.userOwnsList(ctxUser, id)
+	return lp.canWrite(ctxUser, id)
 }
 
 func (lp *listPolicy) CanDelete(ctxUser middleware.CtxUser, id int64) (bool, error) {
-	return lp.userOwnsList(ctxUser, id)
+	return lp.canWrite(ctxUser, id)
 }
@@ -1,8 +1,8 @@
 package policy
 
 import (
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
-	"xorm.io/xorm"
 )
 
 // Policy defines the authorization interface for checking user permissions
@@ -25,12 +25,34 @@ type Policy interface {
 	CanDelete(ctxUser middleware.CtxUser, id int64) (bool, error)
 }
 
+// BoardPolicy is the Policy for boards. The owner of a board may do anything
+// with it, editors may change it and viewers only view it. Guests have no
+// boards of their own, only the roles they are invited with. Besides checking
+// a single board it can scope queries to the boards a user may view, so that
+// listings and searches filter in the database instead of after loading
+// every row.
+type BoardPolicy interface {
+	Policy
+
+	// CanManageMembers checks if a user can invite users to the board with
+	// the given id and remove its members.
+	CanManageMembers(ctxUser middleware.CtxUser, id int64) (bool, error)
+
+	// CanViewMembers checks if a user can list the owner and members of the
+	// board with the given id. Guests can't list other users.
+	CanViewMembers(ctxUser middleware.CtxUser, id int64) (bool, error)
+
+	// ViewScope returns an SQL condition and its arguments matching rows
+	// whose boardIdColumn refers to a board the user can view.
+	ViewScope(ctxUser middleware.CtxUser, boardIdColumn string) (string, []any)
+}
+
 type Policies struct {
-	BoardPolicy Policy
+	BoardPolicy BoardPolicy
 	ListPolicy  Policy
 }
 
-func InitPolicies(engine *xorm.Engine) *Policies {
+func InitPolicies(engine repository.DB) *Policies {
 	return &Policies{
 		BoardPolicy: NewBoardPolicy(engine),
 		ListPolicy:  NewListPolicy(engine),
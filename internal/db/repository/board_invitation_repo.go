@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+var (
+	ErrBoardInvitationNotFound = apperror.New(apperror.KindNotFound, "board_invitation_not_found", "invitation not found")
+	ErrBoardMemberExists       = apperror.New(apperror.KindConflict, "board_member_exists", "user is already a member of the board")
+	ErrGuestInvitationForUser  = apperror.New(apperror.KindConflict, "guest_invitation_for_user", "guest invitations are only for guests and users without an account")
+)
+
+type BoardInvitationRepository interface {
+	CreateBoardInvitation(args *CreateBoardInvitationArgs) (*models.BoardInvitation, error)
+	GetBoardInvitationById(id int64) (*models.BoardInvitation, error)
+	GetAllBoardInvitationsByBoardId(boardId int64) ([]*models.BoardInvitation, error)
+	DeleteBoardInvitation(args *DeleteBoardInvitationArgs) error
+	AcceptBoardInvitation(args *AcceptBoardInvitationArgs) (*models.BoardMember, error)
+	GetUnsentBoardInvitations(limit int) ([]*models.UnsentBoardInvitation, error)
+	MarkBoardInvitationSent(args *MarkBoardInvitationSentArgs) error
+}
+
+type boardInvitationRepository struct {
+	engine DB
+}
+
+func NewBoardInvitationRepository(engine DB) BoardInvitationRepository {
+	return &boardInvitationRepository{engine}
+}
+
+type CreateBoardInvitationArgs struct {
+	BoardId int64
+	// Email is expected in lower case.
+	Email     string
+	Role      string
+	Guest     bool
+	InvitedBy int64
+	ExpiresAt time.Time
+}
+
+// CreateBoardInvitation invites the email to the board. Inviting an email
+// already invited replaces that invitation, which is emailed again. It fails
+// with ErrBoardMemberExists when the email is of a user with access already,
+// and with ErrGuestInvitationForUser when a guest invitation is for a user
+// who is not a guest.
+func (bir *boardInvitationRepository) CreateBoardInvitation(args *CreateBoardInvitationArgs) (*models.BoardInvitation, error) {
+	if args.Guest {
+		var isUser bool
+
+		_, err := bir.engine.SQL(
+			"SELECT EXISTS (SELECT 1 FROM users WHERE email = ? AND NOT is_guest)",
+			args.Email,
+		).Get(&isUser)
+		if err != nil {
+			return nil, err
+		}
+		if isUser {
+			return nil, ErrGuestInvitationForUser
+		}
+	}
+
+	var isMember bool
+
+	_, err := bir.engine.SQL(`
+		SELECT EXISTS (
+			SELECT 1 FROM users u
+			WHERE u.email = ?
+			AND (
+				u.id IN (SELECT user_id FROM boards WHERE id = ?)
+				OR u.id IN (SELECT user_id FROM board_members WHERE board_id = ?)
+			)
+		)`,
+		args.Email, args.BoardId, args.BoardId,
+	).Get(&isMember)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, ErrBoardMemberExists
+	}
+
+	invitation := &models.BoardInvitation{}
+	now := time.Now()
+
+	_, err = bir.engine.SQL(`
+		INSERT INTO board_invitations (board_id, email, role, guest, invited_by, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (board_id, email) DO UPDATE SET
+			role = EXCLUDED.role,
+			guest = EXCLUDED.guest,
+			invited_by = EXCLUDED.invited_by,
+			expires_at = EXCLUDED.expires_at,
+			sent_at = NULL,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`,
+		args.BoardId, args.Email, args.Role, args.Guest, args.InvitedBy, args.ExpiresAt, now, now,
+	).Get(invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// GetBoardInvitationById returns the invitation unless it expired.
+func (bir *boardInvitationRepository) GetBoardInvitationById(id int64) (*models.BoardInvitation, error) {
+	invitation := &models.BoardInvitation{}
+
+	has, err := bir.engine.
+		Where("id = ? AND expires_at > ?", id, time.Now()).
+		Get(invitation)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrBoardInvitationNotFound
+	}
+
+	return invitation, nil
+}
+
+// GetAllBoardInvitationsByBoardId returns the invitations to the board that
+// did not expire, oldest first.
+func (bir *boardInvitationRepository) GetAllBoardInvitationsByBoardId(boardId int64) ([]*models.BoardInvitation, error) {
+	invitations := []*models.BoardInvitation{}
+
+	err := bir.engine.
+		Where("board_id = ? AND expires_at > ?", boardId, time.Now()).
+		OrderBy("created_at, id").
+		Find(&invitations)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+type DeleteBoardInvitationArgs struct {
+	Id      int64
+	BoardId int64
+}
+
+// DeleteBoardInvitation revokes the invitation. Its link stops working even
+// if the email was sent.
+func (bir *boardInvitationRepository) DeleteBoardInvitation(args *DeleteBoardInvitationArgs) error {
+	affected, err := bir.engine.
+		Where("id = ? AND board_id = ?", args.Id, args.BoardId).
+		Delete(new(models.BoardInvitation))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBoardInvitationNotFound
+	}
+
+	return nil
+}
+
+type AcceptBoardInvitationArgs struct {
+	Id     int64
+	UserId int64
+}
+
+// AcceptBoardInvitation makes the user a member of the board with the role
+// of the invitation, which is used up, and has them watch the board. A
+// member accepting takes the role of the invitation. Guest invitations fail
+// with ErrGuestInvitationForUser for users who are not guests, who would
+// otherwise get in as full members.
+func (bir *boardInvitationRepository) AcceptBoardInvitation(args *AcceptBoardInvitationArgs) (*models.BoardMember, error) {
+	var member *models.BoardMember
+
+	err := inTransaction(bir.engine, func(tx DB) error {
+		invitation := &models.BoardInvitation{}
+
+		has, err := tx.
+			Where("id = ? AND expires_at > ?", args.Id, time.Now()).
+			Get(invitation)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrBoardInvitationNotFound
+		}
+
+		if invitation.Guest {
+			var isGuest bool
+
+			_, err := tx.SQL("SELECT is_guest FROM users WHERE id = ?", args.UserId).Get(&isGuest)
+			if err != nil {
+				return err
+			}
+			if !isGuest {
+				return ErrGuestInvitationForUser
+			}
+		}
+
+		isOwner, err := tx.
+			Where("id = ? AND user_id = ?", invitation.BoardId, args.UserId).
+			Exist(new(models.Board))
+		if err != nil {
+			return err
+		}
+		if isOwner {
+			return ErrBoardMemberExists
+		}
+
+		// Two acceptances of the same invitation race for its deletion.
+		affected, err := tx.
+			Where("id = ?", invitation.Id).
+			Delete(new(models.BoardInvitation))
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrBoardInvitationNotFound
+		}
+
+		member = &models.BoardMember{}
+
+		_, err = tx.SQL(`
+			INSERT INTO board_members (board_id, user_id, role, created_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (board_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING *`,
+			invitation.BoardId, args.UserId, invitation.Role, time.Now(),
+		).Get(member)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO watchers (user_id, board_id, created_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT DO NOTHING`,
+			args.UserId, invitation.BoardId, time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return loadBoardMemberUsers(tx, []*models.BoardMember{member})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// GetUnsentBoardInvitations returns the oldest invitations whose email was
+// not sent yet and that did not expire.
+func (bir *boardInvitationRepository) GetUnsentBoardInvitations(limit int) ([]*models.UnsentBoardInvitation, error) {
+	invitations := []*models.UnsentBoardInvitation{}
+
+	err := bir.engine.SQL(`
+		SELECT i.*, b.name AS board_name, u.name AS inviter_name
+		FROM board_invitations i
+		INNER JOIN boards b ON b.id = i.board_id
+		LEFT JOIN users u ON u.id = i.invited_by
+		WHERE i.sent_at IS NULL
+		AND i.expires_at > ?
+		ORDER BY i.id
+		LIMIT ?`,
+		time.Now(), limit,
+	).Find(&invitations)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+type MarkBoardInvitationSentArgs struct {
+	Id int64
+	// UpdatedAt is when the invitation emailed was last changed. An
+	// invitation replaced since stays unsent, so the new one is emailed too.
+	UpdatedAt time.Time
+}
+
+func (bir *boardInvitationRepository) MarkBoardInvitationSent(args *MarkBoardInvitationSentArgs) error {
+	_, err := bir.engine.Exec(`
+		UPDATE board_invitations SET sent_at = ?
+		WHERE id = ? AND updated_at = ?`,
+		time.Now(), args.Id, args.UpdatedAt,
+	)
+
+	return err
+}
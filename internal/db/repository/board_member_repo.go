@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+var ErrBoardMemberNotFound = apperror.New(apperror.KindNotFound, "board_member_not_found", "board member not found")
+
+type BoardMemberRepository interface {
+	GetAllBoardMembersByBoardId(boardId int64) ([]*models.BoardMember, error)
+	DeleteBoardMember(args *DeleteBoardMemberArgs) error
+}
+
+type boardMemberRepository struct {
+	engine DB
+}
+
+func NewBoardMemberRepository(engine DB) BoardMemberRepository {
+	return &boardMemberRepository{engine}
+}
+
+// GetAllBoardMembersByBoardId returns the owner of the board, with the role
+// models.BoardRoleOwner, followed by its members in the order they joined.
+func (bmr *boardMemberRepository) GetAllBoardMembersByBoardId(boardId int64) ([]*models.BoardMember, error) {
+	members := []*models.BoardMember{}
+
+	err := bmr.engine.SQL(`
+		SELECT * FROM (
+			SELECT id AS board_id, user_id, ?::VARCHAR AS role, created_at FROM boards WHERE id = ?
+			UNION ALL
+			SELECT board_id, user_id, role, created_at FROM board_members WHERE board_id = ?
+		) m
+		ORDER BY m.role = ? DESC, m.created_at, m.user_id`,
+		models.BoardRoleOwner, boardId, boardId, models.BoardRoleOwner,
+	).Find(&members)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadBoardMemberUsers(bmr.engine, members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// loadBoardMemberUsers fills User of each member.
+func loadBoardMemberUsers(engine DB, members []*models.BoardMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	userIds := make([]int64, 0, len(members))
+	for _, member := range members {
+		userIds = append(userIds, member.UserId)
+	}
+
+	users := []*models.User{}
+
+	err := engine.
+		Alias("u").
+		In("u.id", userIds).
+		Find(&users)
+	if err != nil {
+		return err
+	}
+
+	usersById := make(map[int64]*models.User, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	for _, member := range members {
+		member.User = usersById[member.UserId]
+	}
+
+	return nil
+}
+
+type DeleteBoardMemberArgs struct {
+	BoardId int64
+	UserId  int64
+}
+
+// DeleteBoardMember removes the user from the board, and with it the
+// watching of the board and its lists, so they hear no more of it.
+func (bmr *boardMemberRepository) DeleteBoardMember(args *DeleteBoardMemberArgs) error {
+	return inTransaction(bmr.engine, func(tx DB) error {
+		affected, err := tx.
+			Where("board_id = ? AND user_id = ?", args.BoardId, args.UserId).
+			Delete(new(models.BoardMember))
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrBoardMemberNotFound
+		}
+
+		_, err = tx.
+			Where("board_id = ? AND user_id = ?", args.BoardId, args.UserId).
+			Delete(new(models.Watcher))
+
+		return err
+	})
+}
@@ -1,51 +1,271 @@
 package repository
 
 import (
-	"errors"
+	"time"
 
+	"github.com/mithileshgupta12/velaris/internal/apperror"
 	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/event"
+	"github.com/mithileshgupta12/velaris/internal/pagination"
 	"xorm.io/xorm"
 )
 
 var (
-	ErrBoardNotFound       = errors.New("board not found")
-	ErrBoardCreationFailed = errors.New("failed to create board")
+	ErrBoardNotFound       = apperror.New(apperror.KindNotFound, "board_not_found", "board not found")
+	ErrBoardCreationFailed = apperror.New(apperror.KindInternal, "board_creation_failed", "failed to create board")
+	// ErrBoardVersionMismatch is returned when a board was changed after the
+	// version a write was based on.
+	ErrBoardVersionMismatch = apperror.New(apperror.KindPreconditionFailed, "board_version_mismatch", "board has been modified")
 )
 
 type BoardRepository interface {
-	GetAllBoardsByUserId(userId int64) ([]*models.Board, error)
+	GetAllBoardsByUserId(args *GetAllBoardsByUserIdArgs) ([]*models.Board, *pagination.Cursor, error)
 	CreateBoard(args *CreateBoardArgs) (*models.Board, error)
 	GetBoardById(args *GetBoardByIdArgs) (*models.Board, error)
 	UpdateBoardById(args *UpdateBoardByIdArgs) (*models.Board, error)
 	DeleteBoardById(args *DeleteBoardByIdArgs) error
+	ArchiveBoardById(args *ArchiveBoardByIdArgs) (*models.Board, error)
+	UnarchiveBoardById(args *UnarchiveBoardByIdArgs) (*models.Board, error)
+	DuplicateBoardById(args *DuplicateBoardByIdArgs) (*models.Board, error)
+	GetAllTemplateBoardsByUserId(userId int64) ([]*models.Board, error)
+	SetBoardTemplateById(args *SetBoardTemplateByIdArgs) (*models.Board, error)
 }
 
 type boardRepository struct {
-	engine *xorm.Engine
+	engine DB
 }
 
-func NewBoardRepository(engine *xorm.Engine) *boardRepository {
+func NewBoardRepository(engine DB) *boardRepository {
 	return &boardRepository{engine}
 }
 
-func (br *boardRepository) GetAllBoardsByUserId(userId int64) ([]*models.Board, error) {
+// transaction runs f with a copy of the repository whose queries share one
+// transaction, so that a change and the event recording it are saved
+// together.
+func (br *boardRepository) transaction(f func(tx *boardRepository) error) error {
+	return inTransaction(br.engine, func(tx DB) error {
+		return f(&boardRepository{tx})
+	})
+}
+
+// boardIncludes are the relations that can be loaded alongside boards.
+var boardIncludes = map[string]includeLoader[*models.Board]{
+	"lists":    loadBoardLists,
+	"owner":    loadBoardOwners,
+	"watchers": loadBoardWatchers,
+}
+
+// loadBoardLists fills Lists with the active lists of each board in
+// position order.
+func loadBoardLists(engine DB, boards []*models.Board) error {
+	boardIds := make([]int64, 0, len(boards))
+	for _, board := range boards {
+		boardIds = append(boardIds, board.Id)
+	}
+
+	lists := []*models.List{}
+
+	err := engine.
+		Alias("l").
+		In("l.board_id", boardIds).
+		And("l.archived_at IS NULL").
+		OrderBy("l.board_id, l.position, l.id").
+		Find(&lists)
+	if err != nil {
+		return err
+	}
+
+	listsByBoardId := make(map[int64][]*models.List, len(boards))
+	for _, list := range lists {
+		listsByBoardId[list.BoardId] = append(listsByBoardId[list.BoardId], list)
+	}
+
+	for _, board := range boards {
+		board.Lists = listsByBoardId[board.Id]
+		if board.Lists == nil {
+			board.Lists = []*models.List{}
+		}
+	}
+
+	return nil
+}
+
+// loadBoardOwners fills User with the owner of each board.
+func loadBoardOwners(engine DB, boards []*models.Board) error {
+	userIds := make([]int64, 0, len(boards))
+	for _, board := range boards {
+		userIds = append(userIds, board.UserId)
+	}
+
+	users := []*models.PublicUser{}
+
+	err := engine.
+		Alias("u").
+		In("u.id", userIds).
+		Find(&users)
+	if err != nil {
+		return err
+	}
+
+	usersById := make(map[int64]*models.PublicUser, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	for _, board := range boards {
+		board.User = usersById[board.UserId]
+	}
+
+	return nil
+}
+
+// loadBoardWatchers fills Watchers with the users watching each board, in the
+// order they started. Users watching only some lists are left out.
+func loadBoardWatchers(engine DB, boards []*models.Board) error {
+	boardIds := make([]int64, 0, len(boards))
+	for _, board := range boards {
+		boardIds = append(boardIds, board.Id)
+	}
+
+	watchers := []*models.Watcher{}
+
+	err := engine.
+		In("board_id", boardIds).
+		And("list_id IS NULL").
+		OrderBy("created_at, id").
+		Find(&watchers)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]int64, 0, len(watchers))
+	for _, watcher := range watchers {
+		userIds = append(userIds, watcher.UserId)
+	}
+
+	users := []*models.PublicUser{}
+
+	if len(userIds) > 0 {
+		err = engine.
+			Alias("u").
+			In("u.id", userIds).
+			Find(&users)
+		if err != nil {
+			return err
+		}
+	}
+
+	usersById := make(map[int64]*models.PublicUser, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	watchersByBoardId := make(map[int64][]*models.PublicUser, len(boards))
+	for _, watcher := range watchers {
+		if user, ok := usersById[watcher.UserId]; ok {
+			watchersByBoardId[watcher.BoardId] = append(watchersByBoardId[watcher.BoardId], user)
+		}
+	}
+
+	for _, board := range boards {
+		board.Watchers = watchersByBoardId[board.Id]
+		if board.Watchers == nil {
+			board.Watchers = []*models.PublicUser{}
+		}
+	}
+
+	return nil
+}
+
+type GetAllBoardsByUserIdArgs struct {
+	// UserId selects the boards the user owns or is a member of.
+	UserId int64
+	// Archived selects archived boards instead of active ones.
+	Archived bool
+	// Watching restricts the boards to the ones the user watches as a whole.
+	Watching bool
+	// Page limits, orders and filters the boards. Without it every board is
+	// returned.
+	Page *pagination.Query
+	// Include names the relations loaded with the boards, see boardIncludes.
+	Include []string
+}
+
+func (br *boardRepository) GetAllBoardsByUserId(args *GetAllBoardsByUserIdArgs) ([]*models.Board, *pagination.Cursor, error) {
 	boards := []*models.Board{}
 
-	err := br.engine.
+	query := br.engine.
 		Alias("b").
-		Where("b.userId = ?", userId).
-		Find(&boards)
+		Where("(b.user_id = ? OR b.id IN (SELECT board_id FROM board_members WHERE user_id = ?))", args.UserId, args.UserId)
+
+	if args.Archived {
+		query = query.And("b.archived_at IS NOT NULL")
+	} else {
+		query = query.And("b.archived_at IS NULL")
+	}
+
+	if args.Watching {
+		query = query.And("b.id IN (SELECT board_id FROM watchers WHERE user_id = ? AND list_id IS NULL)", args.UserId)
+	}
+
+	if args.Page != nil {
+		query = applyPage(query, "b", args.Page)
+	}
+
+	err := query.Find(&boards)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return boards, nil
+	var next *pagination.Cursor
+	if args.Page != nil {
+		boards, next = nextPage(boards, args.Page, func(board *models.Board) (string, int64) {
+			return boardSortValue(board, args.Page.Sort), board.Id
+		})
+	}
+
+	if err := loadIncludes(br.engine, boards, args.Include, boardIncludes); err != nil {
+		return nil, nil, err
+	}
+
+	return boards, next, nil
+}
+
+// boardSortValue returns the value of the board's sort field as stored in a
+// pagination cursor.
+func boardSortValue(board *models.Board, sort string) string {
+	switch sort {
+	case "name":
+		return board.Name
+	case "updated_at":
+		return board.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return board.CreatedAt.Format(time.RFC3339Nano)
+	}
 }
 
 type CreateBoardArgs struct {
 	Name        string
 	Description *string
 	UserId      int64
+	IsTemplate  bool
+	ArchivedAt  *time.Time
+	// CreatedAt and UpdatedAt keep the timestamps of an imported board. The
+	// board is created now when CreatedAt is zero.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Lists are created on the new board in the same transaction, which is
+	// how boards are instantiated from templates.
+	Lists []*CreateBoardListArgs
+}
+
+type CreateBoardListArgs struct {
+	Name       string
+	Position   int
+	ArchivedAt *time.Time
+	// CreatedAt and UpdatedAt are kept like those of CreateBoardArgs.
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 func (br *boardRepository) CreateBoard(args *CreateBoardArgs) (*models.Board, error) {
@@ -53,22 +273,100 @@ func (br *boardRepository) CreateBoard(args *CreateBoardArgs) (*models.Board, er
 		Name:        args.Name,
 		Description: args.Description,
 		UserId:      args.UserId,
+		IsTemplate:  args.IsTemplate,
+		ArchivedAt:  args.ArchivedAt,
+		CreatedAt:   args.CreatedAt,
+		UpdatedAt:   args.UpdatedAt,
 	}
 
-	affected, err := br.engine.
-		Insert(board)
+	_, err := br.engine.Transaction(func(session *xorm.Session) (any, error) {
+		if err := insertBoardWithLists(session, board, args.Lists); err != nil {
+			return nil, err
+		}
+
+		return nil, recordEvent(session, &recordEventArgs{
+			Event:   event.BoardCreated,
+			BoardId: board.Id,
+			ActorId: args.UserId,
+			Data:    board,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return board, nil
+}
+
+// insertBoardWithLists inserts board and its lists using session and fills
+// board.Lists with the created rows. The owner of the board watches it.
+func insertBoardWithLists(session *xorm.Session, board *models.Board, listArgs []*CreateBoardListArgs) error {
+	affected, err := withTimestamps(session, &board.CreatedAt, &board.UpdatedAt).
+		Insert(board)
+	if err != nil {
+		return err
+	}
 	if affected == 0 {
-		return nil, ErrBoardCreationFailed
+		return ErrBoardCreationFailed
 	}
 
-	return board, nil
+	_, err = session.
+		Insert(&models.Watcher{UserId: board.UserId, BoardId: board.Id})
+	if err != nil {
+		return err
+	}
+
+	if len(listArgs) == 0 {
+		return nil
+	}
+
+	lists := make([]*models.List, 0, len(listArgs))
+	for _, listArg := range listArgs {
+		list := &models.List{
+			Name:       listArg.Name,
+			BoardId:    board.Id,
+			Position:   listArg.Position,
+			ArchivedAt: listArg.ArchivedAt,
+			CreatedAt:  listArg.CreatedAt,
+			UpdatedAt:  listArg.UpdatedAt,
+		}
+
+		affected, err := withTimestamps(session, &list.CreatedAt, &list.UpdatedAt).
+			Insert(list)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrListCreationFailed
+		}
+
+		lists = append(lists, list)
+	}
+
+	board.Lists = lists
+
+	return nil
+}
+
+// withTimestamps keeps createdAt and updatedAt on the next insert of session
+// when createdAt is set, instead of setting both to now. updatedAt is moved
+// up to createdAt when it is zero or earlier.
+func withTimestamps(session *xorm.Session, createdAt, updatedAt *time.Time) *xorm.Session {
+	if createdAt.IsZero() {
+		return session
+	}
+
+	if updatedAt.Before(*createdAt) {
+		*updatedAt = *createdAt
+	}
+
+	return session.NoAutoTime()
 }
 
 type GetBoardByIdArgs struct {
 	Id int64
+	// Include names the relations loaded with the board, see boardIncludes.
+	Include []string
 }
 
 func (br *boardRepository) GetBoardById(args *GetBoardByIdArgs) (*models.Board, error) {
@@ -85,6 +383,10 @@ func (br *boardRepository) GetBoardById(args *GetBoardByIdArgs) (*models.Board,
 		return nil, ErrBoardNotFound
 	}
 
+	if err := loadIncludes(br.engine, []*models.Board{board}, args.Include, boardIncludes); err != nil {
+		return nil, err
+	}
+
 	return board, nil
 }
 
@@ -92,45 +394,288 @@ type UpdateBoardByIdArgs struct {
 	Id          int64
 	Name        string
 	Description *string
+	// Columns limits the update to the named columns. When empty every
+	// updatable column is written, so a nil Description clears it.
+	Columns []string
+	// Version is the version the update is based on. Zero updates whatever
+	// version is current.
+	Version int
+	// ActorId is the user making the change, recorded with its event.
+	ActorId int64
 }
 
 func (br *boardRepository) UpdateBoardById(args *UpdateBoardByIdArgs) (*models.Board, error) {
-	board := &models.Board{
-		Name:        args.Name,
-		Description: args.Description,
-	}
+	var board *models.Board
 
-	affected, err := br.engine.
-		Alias("b").
-		Where("b.id = ?", args.Id).
-		Update(board)
+	err := br.transaction(func(tx *boardRepository) error {
+		changes := &models.Board{
+			Name:        args.Name,
+			Description: args.Description,
+		}
+
+		columns := args.Columns
+		if len(columns) == 0 {
+			columns = []string{"name", "description"}
+		}
+
+		affected, err := versioned(tx.engine.Where("id = ?", args.Id), args.Version).
+			Cols(columns...).
+			Update(changes)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return tx.missingBoardError(args.Id, args.Version)
+		}
+
+		board, err = tx.GetBoardById(&GetBoardByIdArgs{Id: args.Id})
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.BoardUpdated,
+			BoardId: board.Id,
+			ActorId: args.ActorId,
+			Data:    board,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
-	if affected == 0 {
-		return nil, ErrBoardNotFound
-	}
 
 	return board, nil
 }
 
 type DeleteBoardByIdArgs struct {
 	Id int64
+	// Version is the version the deletion is based on. Zero deletes whatever
+	// version is current.
+	Version int
+	ActorId int64
 }
 
 func (br *boardRepository) DeleteBoardById(args *DeleteBoardByIdArgs) error {
-	board := &models.Board{
-		Id: args.Id,
+	return br.transaction(func(tx *boardRepository) error {
+		query := tx.engine.Where("id = ?", args.Id)
+		if args.Version != 0 {
+			query = query.And("version = ?", args.Version)
+		}
+
+		affected, err := query.
+			Delete(new(models.Board))
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return tx.missingBoardError(args.Id, args.Version)
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.BoardDeleted,
+			BoardId: args.Id,
+			ActorId: args.ActorId,
+			Data:    map[string]int64{"id": args.Id},
+		})
+	})
+}
+
+// missingBoardError explains why a write to the board matched no row: the
+// board is gone, or it was changed after expectedVersion.
+func (br *boardRepository) missingBoardError(id int64, expectedVersion int) error {
+	if expectedVersion == 0 {
+		return ErrBoardNotFound
 	}
 
-	affected, err := br.engine.
-		Delete(board)
+	exists, err := br.engine.
+		Where("id = ?", id).
+		Exist(new(models.Board))
 	if err != nil {
 		return err
 	}
-	if affected == 0 {
-		return ErrBoardNotFound
+	if exists {
+		return ErrBoardVersionMismatch
 	}
 
-	return nil
+	return ErrBoardNotFound
+}
+
+type ArchiveBoardByIdArgs struct {
+	Id      int64
+	ActorId int64
+}
+
+func (br *boardRepository) ArchiveBoardById(args *ArchiveBoardByIdArgs) (*models.Board, error) {
+	now := time.Now()
+
+	return br.setBoardArchivedAt(args.Id, &now, args.ActorId)
+}
+
+type UnarchiveBoardByIdArgs struct {
+	Id      int64
+	ActorId int64
+}
+
+func (br *boardRepository) UnarchiveBoardById(args *UnarchiveBoardByIdArgs) (*models.Board, error) {
+	return br.setBoardArchivedAt(args.Id, nil, args.ActorId)
+}
+
+// setBoardArchivedAt archives the board at archivedAt, or unarchives it when
+// archivedAt is nil. Archiving an archived board, or unarchiving an active
+// one, changes nothing and records no event.
+func (br *boardRepository) setBoardArchivedAt(id int64, archivedAt *time.Time, actorId int64) (*models.Board, error) {
+	var board *models.Board
+
+	err := br.transaction(func(tx *boardRepository) error {
+		condition, eventName := "id = ? AND archived_at IS NULL", event.BoardArchived
+		if archivedAt == nil {
+			condition, eventName = "id = ? AND archived_at IS NOT NULL", event.BoardUnarchived
+		}
+
+		affected, err := versioned(tx.engine.Where(condition, id), 0).
+			Cols("archived_at").
+			Update(&models.Board{ArchivedAt: archivedAt})
+		if err != nil {
+			return err
+		}
+
+		board, err = tx.GetBoardById(&GetBoardByIdArgs{Id: id})
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   eventName,
+			BoardId: board.Id,
+			ActorId: actorId,
+			Data:    board,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return board, nil
+}
+
+type DuplicateBoardByIdArgs struct {
+	Id     int64
+	UserId int64
+	Name   string
+}
+
+func (br *boardRepository) DuplicateBoardById(args *DuplicateBoardByIdArgs) (*models.Board, error) {
+	result, err := br.engine.Transaction(func(session *xorm.Session) (any, error) {
+		source := new(models.Board)
+
+		has, err := session.
+			Where("id = ?", args.Id).
+			Get(source)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			return nil, ErrBoardNotFound
+		}
+
+		sourceLists := []*models.List{}
+
+		err = session.
+			Where("board_id = ?", args.Id).
+			OrderBy("position, id").
+			Find(&sourceLists)
+		if err != nil {
+			return nil, err
+		}
+
+		listArgs := make([]*CreateBoardListArgs, 0, len(sourceLists))
+		for _, list := range sourceLists {
+			listArgs = append(listArgs, &CreateBoardListArgs{
+				Name:       list.Name,
+				Position:   list.Position,
+				ArchivedAt: list.ArchivedAt,
+			})
+		}
+
+		board := &models.Board{
+			Name:        args.Name,
+			Description: source.Description,
+			UserId:      args.UserId,
+		}
+
+		if err := insertBoardWithLists(session, board, listArgs); err != nil {
+			return nil, err
+		}
+
+		err = recordEvent(session, &recordEventArgs{
+			Event:   event.BoardCreated,
+			BoardId: board.Id,
+			ActorId: args.UserId,
+			Data:    board,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return board, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*models.Board), nil
+}
+
+func (br *boardRepository) GetAllTemplateBoardsByUserId(userId int64) ([]*models.Board, error) {
+	boards := []*models.Board{}
+
+	err := br.engine.
+		Alias("b").
+		Where("b.user_id = ? AND b.is_template AND b.archived_at IS NULL", userId).
+		OrderBy("b.name, b.id").
+		Find(&boards)
+	if err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
+type SetBoardTemplateByIdArgs struct {
+	Id         int64
+	IsTemplate bool
+	ActorId    int64
+}
+
+func (br *boardRepository) SetBoardTemplateById(args *SetBoardTemplateByIdArgs) (*models.Board, error) {
+	var board *models.Board
+
+	err := br.transaction(func(tx *boardRepository) error {
+		affected, err := versioned(tx.engine.Where("id = ?", args.Id), 0).
+			Cols("is_template").
+			Update(&models.Board{IsTemplate: args.IsTemplate})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrBoardNotFound
+		}
+
+		board, err = tx.GetBoardById(&GetBoardByIdArgs{Id: args.Id})
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.BoardUpdated,
+			BoardId: board.Id,
+			ActorId: args.ActorId,
+			Data:    board,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return board, nil
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+)
+
+var ErrBoardShareLinkNotFound = apperror.New(apperror.KindNotFound, "share_link_not_found", "share link not found")
+
+type BoardShareLinkRepository interface {
+	CreateBoardShareLink(args *CreateBoardShareLinkArgs) (*models.BoardShareLink, error)
+	GetAllBoardShareLinksByBoardId(boardId int64) ([]*models.BoardShareLink, error)
+	GetBoardShareLinkByToken(token string) (*models.BoardShareLink, error)
+	DeleteBoardShareLink(args *DeleteBoardShareLinkArgs) error
+}
+
+type boardShareLinkRepository struct {
+	engine DB
+}
+
+func NewBoardShareLinkRepository(engine DB) BoardShareLinkRepository {
+	return &boardShareLinkRepository{engine}
+}
+
+type CreateBoardShareLinkArgs struct {
+	BoardId int64
+	// PasswordHash is the hash of the password asked for by the link, nil
+	// for none.
+	PasswordHash *string
+	// ExpiresAt is nil for links that work until revoked.
+	ExpiresAt *time.Time
+	CreatedBy int64
+}
+
+// CreateBoardShareLink creates a link to the board with a new random token,
+// which is only returned here.
+func (bslr *boardShareLinkRepository) CreateBoardShareLink(args *CreateBoardShareLinkArgs) (*models.BoardShareLink, error) {
+	token := rand.Text()
+
+	link := &models.BoardShareLink{
+		BoardId:      args.BoardId,
+		TokenHash:    helper.HashToken(token),
+		PasswordHash: args.PasswordHash,
+		ExpiresAt:    args.ExpiresAt,
+		CreatedBy:    &args.CreatedBy,
+	}
+
+	if _, err := bslr.engine.Insert(link); err != nil {
+		return nil, err
+	}
+
+	link.Token = token
+	link.HasPassword = link.PasswordHash != nil
+
+	return link, nil
+}
+
+// GetAllBoardShareLinksByBoardId returns the links to the board that did not
+// expire, oldest first.
+func (bslr *boardShareLinkRepository) GetAllBoardShareLinksByBoardId(boardId int64) ([]*models.BoardShareLink, error) {
+	links := []*models.BoardShareLink{}
+
+	err := bslr.engine.
+		Where("board_id = ? AND (expires_at IS NULL OR expires_at > ?)", boardId, time.Now()).
+		OrderBy("created_at, id").
+		Find(&links)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		link.HasPassword = link.PasswordHash != nil
+	}
+
+	return links, nil
+}
+
+// GetBoardShareLinkByToken returns the link with the token unless it expired.
+func (bslr *boardShareLinkRepository) GetBoardShareLinkByToken(token string) (*models.BoardShareLink, error) {
+	link := &models.BoardShareLink{}
+
+	has, err := bslr.engine.
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", helper.HashToken(token), time.Now()).
+		Get(link)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrBoardShareLinkNotFound
+	}
+
+	link.HasPassword = link.PasswordHash != nil
+
+	return link, nil
+}
+
+type DeleteBoardShareLinkArgs struct {
+	Id      int64
+	BoardId int64
+}
+
+// DeleteBoardShareLink revokes the link.
+func (bslr *boardShareLinkRepository) DeleteBoardShareLink(args *DeleteBoardShareLinkArgs) error {
+	affected, err := bslr.engine.
+		Where("id = ? AND board_id = ?", args.Id, args.BoardId).
+		Delete(new(models.BoardShareLink))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBoardShareLinkNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import "xorm.io/xorm"
+
+// DB is what repositories and policies query through: the engine, or a
+// transaction shared by several of them.
+type DB interface {
+	xorm.Interface
+	// Transaction runs f in a transaction. When DB already is a transaction f
+	// runs in it, so f's changes commit or roll back with the outer one.
+	Transaction(f func(*xorm.Session) (any, error)) (any, error)
+}
+
+// txDB is a DB bound to the session of an open transaction.
+type txDB struct {
+	*xorm.Session
+}
+
+func (tx txDB) Transaction(f func(*xorm.Session) (any, error)) (any, error) {
+	return f(tx.Session)
+}
+
+// inTransaction runs f with a DB bound to a transaction: db itself when it
+// already is one, or a new transaction that commits when f returns nil and
+// rolls back otherwise.
+func inTransaction(db DB, f func(tx DB) error) error {
+	_, err := db.Transaction(func(session *xorm.Session) (any, error) {
+		return nil, f(txDB{session})
+	})
+
+	return err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+type DigestRepository interface {
+	GetDigestSettingByUserId(userId int64) (*models.DigestSetting, error)
+	SetDigestFrequency(args *SetDigestFrequencyArgs) (*models.DigestSetting, error)
+	GetDigestUserIds(frequency string) ([]int64, error)
+	GetDigestNotificationsByUserId(args *GetDigestNotificationsByUserIdArgs) ([]*models.DigestNotification, error)
+	MarkDigestSent(args *MarkDigestSentArgs) error
+}
+
+type digestRepository struct {
+	engine DB
+}
+
+func NewDigestRepository(engine DB) DigestRepository {
+	return &digestRepository{engine}
+}
+
+// GetDigestSettingByUserId returns the digest setting of the user, or the
+// default one if they never changed it.
+func (dr *digestRepository) GetDigestSettingByUserId(userId int64) (*models.DigestSetting, error) {
+	setting := &models.DigestSetting{}
+
+	has, err := dr.engine.
+		Where("user_id = ?", userId).
+		Get(setting)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return &models.DigestSetting{UserId: userId, Frequency: models.DefaultDigestFrequency}, nil
+	}
+
+	return setting, nil
+}
+
+type SetDigestFrequencyArgs struct {
+	UserId    int64
+	Frequency string
+}
+
+func (dr *digestRepository) SetDigestFrequency(args *SetDigestFrequencyArgs) (*models.DigestSetting, error) {
+	_, err := dr.engine.Exec(`
+		INSERT INTO digest_settings (user_id, frequency, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET frequency = EXCLUDED.frequency, updated_at = EXCLUDED.updated_at`,
+		args.UserId, args.Frequency, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return dr.GetDigestSettingByUserId(args.UserId)
+}
+
+// GetDigestUserIds returns the users with the given digest frequency who have
+// unread notifications not sent in a digest yet.
+func (dr *digestRepository) GetDigestUserIds(frequency string) ([]int64, error) {
+	userIds := []int64{}
+
+	err := dr.engine.SQL(`
+		SELECT DISTINCT n.user_id
+		FROM notifications n
+		LEFT JOIN digest_settings s ON s.user_id = n.user_id
+		WHERE n.read_at IS NULL
+		AND n.id > COALESCE(s.last_notification_id, 0)
+		AND COALESCE(s.frequency, ?) = ?`,
+		models.DefaultDigestFrequency, frequency,
+	).Find(&userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIds, nil
+}
+
+type GetDigestNotificationsByUserIdArgs struct {
+	UserId int64
+	// Scope restricts the notifications to boards the user can view. It is
+	// an SQL condition on the board id column "n.board_id", as returned by
+	// policy.BoardPolicy.ViewScope.
+	Scope     string
+	ScopeArgs []any
+	Limit     int
+}
+
+// GetDigestNotificationsByUserId returns the oldest unread notifications of
+// the user that were not sent in a digest yet.
+func (dr *digestRepository) GetDigestNotificationsByUserId(args *GetDigestNotificationsByUserIdArgs) ([]*models.DigestNotification, error) {
+	notifications := []*models.DigestNotification{}
+
+	queryArgs := []any{args.UserId, args.UserId}
+	queryArgs = append(queryArgs, args.ScopeArgs...)
+	queryArgs = append(queryArgs, args.Limit)
+
+	err := dr.engine.SQL(fmt.Sprintf(`
+		SELECT n.*, b.name AS board_name, u.name AS actor_name
+		FROM notifications n
+		LEFT JOIN boards b ON b.id = n.board_id
+		LEFT JOIN users u ON u.id = n.actor_id
+		WHERE n.user_id = ?
+		AND n.read_at IS NULL
+		AND n.id > COALESCE((SELECT last_notification_id FROM digest_settings WHERE user_id = ?), 0)
+		AND %s
+		ORDER BY n.id
+		LIMIT ?`, args.Scope),
+		queryArgs...,
+	).Find(&notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+type MarkDigestSentArgs struct {
+	UserId int64
+	// LastNotificationId is the newest notification the digest held.
+	LastNotificationId int64
+}
+
+func (dr *digestRepository) MarkDigestSent(args *MarkDigestSentArgs) error {
+	now := time.Now()
+
+	_, err := dr.engine.Exec(`
+		INSERT INTO digest_settings (user_id, frequency, last_notification_id, sent_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			last_notification_id = GREATEST(digest_settings.last_notification_id, EXCLUDED.last_notification_id),
+			sent_at = EXCLUDED.sent_at,
+			updated_at = EXCLUDED.updated_at`,
+		args.UserId, models.DefaultDigestFrequency, args.LastNotificationId, now, now,
+	)
+
+	return err
+}
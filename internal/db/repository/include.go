@@ -0,0 +1,28 @@
+package repository
+
+import "fmt"
+
+// includeLoader loads one relation of a resource for a batch of items. A
+// loader issues a fixed number of queries however many items it is given, so
+// including a relation never turns into one query per item.
+type includeLoader[T any] func(engine DB, items []T) error
+
+// loadIncludes runs the loader of every relation in includes over items.
+func loadIncludes[T any](engine DB, items []T, includes []string, loaders map[string]includeLoader[T]) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, include := range includes {
+		loader, ok := loaders[include]
+		if !ok {
+			return fmt.Errorf("unknown include %q", include)
+		}
+
+		if err := loader(engine, items); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
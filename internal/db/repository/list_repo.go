@@ -1,39 +1,179 @@
 package repository
 
 import (
-	"errors"
+	"strconv"
+	"time"
 
+	"github.com/mithileshgupta12/velaris/internal/apperror"
 	"github.com/mithileshgupta12/velaris/internal/db/models"
-	"xorm.io/xorm"
+	"github.com/mithileshgupta12/velaris/internal/event"
+	"github.com/mithileshgupta12/velaris/internal/pagination"
 )
 
 var (
-	ErrListNotFound = errors.New("list not found")
+	ErrListNotFound       = apperror.New(apperror.KindNotFound, "list_not_found", "list not found")
+	ErrListCreationFailed = apperror.New(apperror.KindInternal, "list_creation_failed", "failed to create list")
+	// ErrListVersionMismatch is returned when a list was changed after the
+	// version a write was based on.
+	ErrListVersionMismatch = apperror.New(apperror.KindPreconditionFailed, "list_version_mismatch", "list has been modified")
 )
 
 type ListRepository interface {
-	GetAllListsByBoardId(args *GetAllListsByBoardIdArgs) ([]*models.List, error)
+	GetAllListsByBoardId(args *GetAllListsByBoardIdArgs) ([]*models.List, *pagination.Cursor, error)
+	GetAllListsByBoardIds(args *GetAllListsByBoardIdsArgs) ([]*models.List, error)
+	CreateList(args *CreateListArgs) (*models.List, error)
+	GetListById(args *GetListByIdArgs) (*models.List, error)
+	UpdateListById(args *UpdateListByIdArgs) (*models.List, error)
 	DeleteListById(args *DeleteListByIdArgs) error
+	ArchiveListById(args *ArchiveListByIdArgs) (*models.List, error)
+	UnarchiveListById(args *UnarchiveListByIdArgs) (*models.List, error)
 }
 
 type listRepository struct {
-	engine *xorm.Engine
+	engine DB
 }
 
-func NewListRepository(engine *xorm.Engine) ListRepository {
+func NewListRepository(engine DB) ListRepository {
 	return &listRepository{engine}
 }
 
+// transaction runs f with a copy of the repository whose queries share one
+// transaction, so that a change and the event recording it are saved
+// together.
+func (lr *listRepository) transaction(f func(tx *listRepository) error) error {
+	return inTransaction(lr.engine, func(tx DB) error {
+		return f(&listRepository{tx})
+	})
+}
+
+// listIncludes are the relations that can be loaded alongside lists.
+var listIncludes = map[string]includeLoader[*models.List]{
+	"board": loadListBoards,
+}
+
+// loadListBoards fills Board with the board each list belongs to.
+func loadListBoards(engine DB, lists []*models.List) error {
+	boardIds := make([]int64, 0, len(lists))
+	for _, list := range lists {
+		boardIds = append(boardIds, list.BoardId)
+	}
+
+	boards := []*models.Board{}
+
+	err := engine.
+		Alias("b").
+		In("b.id", boardIds).
+		Find(&boards)
+	if err != nil {
+		return err
+	}
+
+	boardsById := make(map[int64]*models.Board, len(boards))
+	for _, board := range boards {
+		boardsById[board.Id] = board
+	}
+
+	for _, list := range lists {
+		list.Board = boardsById[list.BoardId]
+	}
+
+	return nil
+}
+
 type GetAllListsByBoardIdArgs struct {
 	BoardId int64
+	// Archived selects archived lists instead of active ones.
+	Archived bool
+	// WithArchived returns archived and active lists together and takes
+	// precedence over Archived.
+	WithArchived bool
+	// Page limits, orders and filters the lists. Without it every list is
+	// returned in position order.
+	Page *pagination.Query
+	// Include names the relations loaded with the lists, see listIncludes.
+	Include []string
 }
 
-func (lr *listRepository) GetAllListsByBoardId(args *GetAllListsByBoardIdArgs) ([]*models.List, error) {
+func (lr *listRepository) GetAllListsByBoardId(args *GetAllListsByBoardIdArgs) ([]*models.List, *pagination.Cursor, error) {
 	lists := []*models.List{}
 
-	err := lr.engine.
+	query := lr.engine.
 		Alias("l").
-		Where("l.board_id = ?", args.BoardId).
+		Where("l.board_id = ?", args.BoardId)
+
+	if !args.WithArchived {
+		if args.Archived {
+			query = query.And("l.archived_at IS NOT NULL")
+		} else {
+			query = query.And("l.archived_at IS NULL")
+		}
+	}
+
+	if args.Page != nil {
+		query = applyPage(query, "l", args.Page)
+	} else {
+		query = query.OrderBy("l.position, l.id")
+	}
+
+	err := query.Find(&lists)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *pagination.Cursor
+	if args.Page != nil {
+		lists, next = nextPage(lists, args.Page, func(list *models.List) (string, int64) {
+			return listSortValue(list, args.Page.Sort), list.Id
+		})
+	}
+
+	if err := loadIncludes(lr.engine, lists, args.Include, listIncludes); err != nil {
+		return nil, nil, err
+	}
+
+	return lists, next, nil
+}
+
+// listSortValue returns the value of the list's sort field as stored in a
+// pagination cursor.
+func listSortValue(list *models.List, sort string) string {
+	switch sort {
+	case "name":
+		return list.Name
+	case "created_at":
+		return list.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return list.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.Itoa(list.Position)
+	}
+}
+
+type GetAllListsByBoardIdsArgs struct {
+	BoardIds []int64
+	// Archived selects archived lists instead of active ones.
+	Archived bool
+}
+
+func (lr *listRepository) GetAllListsByBoardIds(args *GetAllListsByBoardIdsArgs) ([]*models.List, error) {
+	lists := []*models.List{}
+
+	if len(args.BoardIds) == 0 {
+		return lists, nil
+	}
+
+	query := lr.engine.
+		Alias("l").
+		In("l.board_id", args.BoardIds)
+
+	if args.Archived {
+		query = query.And("l.archived_at IS NOT NULL")
+	} else {
+		query = query.And("l.archived_at IS NULL")
+	}
+
+	err := query.
+		OrderBy("l.board_id, l.position, l.id").
 		Find(&lists)
 	if err != nil {
 		return nil, err
@@ -42,23 +182,264 @@ func (lr *listRepository) GetAllListsByBoardId(args *GetAllListsByBoardIdArgs) (
 	return lists, nil
 }
 
+type CreateListArgs struct {
+	Name    string
+	BoardId int64
+	// Position places the list on the board. When nil the list is appended
+	// after the last list of the board.
+	Position *int
+	// ActorId is the user making the change, recorded with its event.
+	ActorId int64
+}
+
+func (lr *listRepository) CreateList(args *CreateListArgs) (*models.List, error) {
+	list := &models.List{
+		Name:    args.Name,
+		BoardId: args.BoardId,
+	}
+
+	err := lr.transaction(func(tx *listRepository) error {
+		if args.Position != nil {
+			list.Position = *args.Position
+		} else {
+			var maxPosition int
+
+			_, err := tx.engine.
+				SQL("SELECT COALESCE(MAX(position), 0) FROM lists WHERE board_id = ?", args.BoardId).
+				Get(&maxPosition)
+			if err != nil {
+				return err
+			}
+
+			list.Position = maxPosition + 1
+		}
+
+		affected, err := tx.engine.
+			Insert(list)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrListCreationFailed
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.ListCreated,
+			BoardId: list.BoardId,
+			ActorId: args.ActorId,
+			Data:    list,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+type GetListByIdArgs struct {
+	Id      int64
+	BoardId int64
+	// Include names the relations loaded with the list, see listIncludes.
+	Include []string
+}
+
+func (lr *listRepository) GetListById(args *GetListByIdArgs) (*models.List, error) {
+	list := new(models.List)
+
+	has, err := lr.engine.
+		Alias("l").
+		Where("l.id = ? AND l.board_id = ?", args.Id, args.BoardId).
+		Get(list)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrListNotFound
+	}
+
+	if err := loadIncludes(lr.engine, []*models.List{list}, args.Include, listIncludes); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+type UpdateListByIdArgs struct {
+	Id      int64
+	BoardId int64
+	Name    string
+	// Position moves the list when set and leaves it in place otherwise.
+	Position *int
+	// Columns limits the update to the named columns. When empty the name is
+	// written, and the position too when Position is set.
+	Columns []string
+	// Version is the version the update is based on. Zero updates whatever
+	// version is current.
+	Version int
+	ActorId int64
+}
+
+func (lr *listRepository) UpdateListById(args *UpdateListByIdArgs) (*models.List, error) {
+	var list *models.List
+
+	err := lr.transaction(func(tx *listRepository) error {
+		changes := &models.List{
+			Name: args.Name,
+		}
+
+		columns := args.Columns
+		if len(columns) == 0 {
+			columns = []string{"name"}
+		}
+
+		if args.Position != nil {
+			changes.Position = *args.Position
+
+			if len(args.Columns) == 0 {
+				columns = append(columns, "position")
+			}
+		}
+
+		affected, err := versioned(tx.engine.Where("id = ? AND board_id = ?", args.Id, args.BoardId), args.Version).
+			Cols(columns...).
+			Update(changes)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return tx.missingListError(args.Id, args.BoardId, args.Version)
+		}
+
+		list, err = tx.GetListById(&GetListByIdArgs{Id: args.Id, BoardId: args.BoardId})
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.ListUpdated,
+			BoardId: list.BoardId,
+			ActorId: args.ActorId,
+			Data:    list,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
 type DeleteListByIdArgs struct {
-	ListId int64
+	ListId  int64
+	BoardId int64
+	// Version is the version the deletion is based on. Zero deletes whatever
+	// version is current.
+	Version int
+	ActorId int64
 }
 
 func (lr *listRepository) DeleteListById(args *DeleteListByIdArgs) error {
-	list := &models.List{
-		Id: args.ListId,
+	return lr.transaction(func(tx *listRepository) error {
+		query := tx.engine.Where("id = ? AND board_id = ?", args.ListId, args.BoardId)
+		if args.Version != 0 {
+			query = query.And("version = ?", args.Version)
+		}
+
+		affected, err := query.
+			Delete(new(models.List))
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return tx.missingListError(args.ListId, args.BoardId, args.Version)
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   event.ListDeleted,
+			BoardId: args.BoardId,
+			ActorId: args.ActorId,
+			Data:    map[string]int64{"id": args.ListId, "board_id": args.BoardId},
+		})
+	})
+}
+
+// missingListError explains why a write to the list matched no row: the list
+// is gone, or it was changed after expectedVersion.
+func (lr *listRepository) missingListError(id, boardId int64, expectedVersion int) error {
+	if expectedVersion == 0 {
+		return ErrListNotFound
 	}
 
-	affected, err := lr.engine.
-		Delete(list)
+	exists, err := lr.engine.
+		Where("id = ? AND board_id = ?", id, boardId).
+		Exist(new(models.List))
 	if err != nil {
 		return err
 	}
-	if affected == 0 {
-		return ErrListNotFound
+	if exists {
+		return ErrListVersionMismatch
 	}
 
-	return nil
+	return ErrListNotFound
+}
+
+type ArchiveListByIdArgs struct {
+	Id      int64
+	BoardId int64
+	ActorId int64
+}
+
+func (lr *listRepository) ArchiveListById(args *ArchiveListByIdArgs) (*models.List, error) {
+	now := time.Now()
+
+	return lr.setListArchivedAt(args.Id, args.BoardId, &now, args.ActorId)
+}
+
+type UnarchiveListByIdArgs struct {
+	Id      int64
+	BoardId int64
+	ActorId int64
+}
+
+func (lr *listRepository) UnarchiveListById(args *UnarchiveListByIdArgs) (*models.List, error) {
+	return lr.setListArchivedAt(args.Id, args.BoardId, nil, args.ActorId)
+}
+
+// setListArchivedAt archives the list at archivedAt, or unarchives it when
+// archivedAt is nil. Archiving an archived list, or unarchiving an active
+// one, changes nothing and records no event.
+func (lr *listRepository) setListArchivedAt(id, boardId int64, archivedAt *time.Time, actorId int64) (*models.List, error) {
+	var list *models.List
+
+	err := lr.transaction(func(tx *listRepository) error {
+		condition, eventName := "id = ? AND board_id = ? AND archived_at IS NULL", event.ListArchived
+		if archivedAt == nil {
+			condition, eventName = "id = ? AND board_id = ? AND archived_at IS NOT NULL", event.ListUnarchived
+		}
+
+		affected, err := versioned(tx.engine.Where(condition, id, boardId), 0).
+			Cols("archived_at").
+			Update(&models.List{ArchivedAt: archivedAt})
+		if err != nil {
+			return err
+		}
+
+		list, err = tx.GetListById(&GetListByIdArgs{Id: id, BoardId: boardId})
+		if err != nil || affected == 0 {
+			return err
+		}
+
+		return recordEvent(tx.engine, &recordEventArgs{
+			Event:   eventName,
+			BoardId: list.BoardId,
+			ActorId: actorId,
+			Data:    list,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/pagination"
+	"xorm.io/xorm"
+)
+
+var ErrNotificationNotFound = apperror.New(apperror.KindNotFound, "notification_not_found", "notification not found")
+
+type NotificationRepository interface {
+	CreateNotificationsForEvent(args *CreateNotificationsForEventArgs) ([]*models.Notification, error)
+	GetAllNotificationsByUserId(args *GetAllNotificationsByUserIdArgs) ([]*models.Notification, *pagination.Cursor, error)
+	CountUnreadNotificationsByUserId(userId int64) (int64, error)
+	MarkNotificationRead(args *MarkNotificationReadArgs) (*models.Notification, error)
+	MarkAllNotificationsRead(userId int64) (int64, error)
+	GetNotificationPreferencesByUserId(userId int64) ([]*models.NotificationPreference, error)
+	SetNotificationPreferences(args *SetNotificationPreferencesArgs) error
+}
+
+type notificationRepository struct {
+	engine DB
+}
+
+func NewNotificationRepository(engine DB) NotificationRepository {
+	return &notificationRepository{engine}
+}
+
+// notificationRecipients selects the users interested in an event: the
+// watchers of its board, given as the first argument, and the watchers of
+// its list, given as the second.
+const notificationRecipients = `
+	SELECT user_id FROM watchers WHERE board_id = ? AND list_id IS NULL
+	UNION
+	SELECT user_id FROM watchers WHERE list_id = ?`
+
+type CreateNotificationsForEventArgs struct {
+	// EventId is the outbox id of the event. A user gets one notification
+	// per event however often it is handled.
+	EventId int64
+	Event   string
+	BoardId int64
+	// ListId is the list the event is about, zero for board events.
+	ListId int64
+	// ActorId is the user who caused the event, who is not notified of it.
+	// Zero when unknown.
+	ActorId int64
+	Data    string
+}
+
+// CreateNotificationsForEvent notifies the users watching the board or list
+// of the event, except its actor and the users who turned the event off, and
+// returns the notifications created.
+func (nr *notificationRepository) CreateNotificationsForEvent(args *CreateNotificationsForEventArgs) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+
+	var actorId *int64
+	if args.ActorId != 0 {
+		actorId = &args.ActorId
+	}
+
+	err := nr.engine.SQL(`
+		INSERT INTO notifications (user_id, board_id, event_id, event, actor_id, data, created_at)
+		SELECT DISTINCT r.user_id, ?::BIGINT, ?::BIGINT, ?, ?::BIGINT, ?, ?::TIMESTAMPTZ
+		FROM (`+notificationRecipients+`) r
+		WHERE r.user_id <> ?
+		AND NOT EXISTS (
+			SELECT 1 FROM notification_preferences p
+			WHERE p.user_id = r.user_id AND p.event = ? AND NOT p.enabled
+		)
+		ON CONFLICT (user_id, event_id) DO NOTHING
+		RETURNING *`,
+		args.BoardId, args.EventId, args.Event, actorId, args.Data, time.Now(),
+		args.BoardId, args.ListId,
+		args.ActorId,
+		args.Event,
+	).Find(&notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+type GetAllNotificationsByUserIdArgs struct {
+	UserId int64
+	// Unread restricts the result to notifications not read yet.
+	Unread bool
+	Page   *pagination.Query
+}
+
+func (nr *notificationRepository) GetAllNotificationsByUserId(args *GetAllNotificationsByUserIdArgs) ([]*models.Notification, *pagination.Cursor, error) {
+	notifications := []*models.Notification{}
+
+	query := nr.engine.
+		Alias("n").
+		Where("n.user_id = ?", args.UserId)
+
+	if args.Unread {
+		query = query.And("n.read_at IS NULL")
+	}
+
+	if args.Page != nil {
+		query = applyPage(query, "n", args.Page)
+	} else {
+		query = query.OrderBy("n.id DESC")
+	}
+
+	if err := query.Find(&notifications); err != nil {
+		return nil, nil, err
+	}
+
+	var next *pagination.Cursor
+	if args.Page != nil {
+		notifications, next = nextPage(notifications, args.Page, func(notification *models.Notification) (string, int64) {
+			return notification.CreatedAt.Format(time.RFC3339Nano), notification.Id
+		})
+	}
+
+	return notifications, next, nil
+}
+
+func (nr *notificationRepository) CountUnreadNotificationsByUserId(userId int64) (int64, error) {
+	return nr.engine.
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Count(new(models.Notification))
+}
+
+type MarkNotificationReadArgs struct {
+	Id     int64
+	UserId int64
+}
+
+// MarkNotificationRead marks a notification of the user read. Marking it
+// again keeps the time it was first read.
+func (nr *notificationRepository) MarkNotificationRead(args *MarkNotificationReadArgs) (*models.Notification, error) {
+	readAt := time.Now()
+
+	_, err := nr.engine.
+		Where("id = ? AND user_id = ? AND read_at IS NULL", args.Id, args.UserId).
+		Cols("read_at").
+		Update(&models.Notification{ReadAt: &readAt})
+	if err != nil {
+		return nil, err
+	}
+
+	notification := &models.Notification{}
+
+	has, err := nr.engine.
+		Where("id = ? AND user_id = ?", args.Id, args.UserId).
+		Get(notification)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrNotificationNotFound
+	}
+
+	return notification, nil
+}
+
+// MarkAllNotificationsRead marks every unread notification of the user read
+// and returns how many there were.
+func (nr *notificationRepository) MarkAllNotificationsRead(userId int64) (int64, error) {
+	readAt := time.Now()
+
+	return nr.engine.
+		Where("user_id = ? AND read_at IS NULL", userId).
+		Cols("read_at").
+		Update(&models.Notification{ReadAt: &readAt})
+}
+
+func (nr *notificationRepository) GetNotificationPreferencesByUserId(userId int64) ([]*models.NotificationPreference, error) {
+	preferences := []*models.NotificationPreference{}
+
+	err := nr.engine.
+		Where("user_id = ?", userId).
+		OrderBy("event").
+		Find(&preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+type SetNotificationPreferencesArgs struct {
+	UserId int64
+	// Events maps event names to whether they notify. Events left out keep
+	// their preference.
+	Events map[string]bool
+}
+
+func (nr *notificationRepository) SetNotificationPreferences(args *SetNotificationPreferencesArgs) error {
+	_, err := nr.engine.Transaction(func(session *xorm.Session) (any, error) {
+		for event, enabled := range args.Events {
+			_, err := session.Exec(`
+				INSERT INTO notification_preferences (user_id, event, enabled)
+				VALUES (?, ?, ?)
+				ON CONFLICT (user_id, event) DO UPDATE SET enabled = EXCLUDED.enabled`,
+				args.UserId, event, enabled,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
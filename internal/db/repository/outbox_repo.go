@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"xorm.io/xorm"
+)
+
+type OutboxRepository interface {
+	PublishOutboxEvents(args *PublishOutboxEventsArgs) (int, error)
+	DeletePublishedOutboxEvents(args *DeletePublishedOutboxEventsArgs) (int64, error)
+}
+
+type outboxRepository struct {
+	engine DB
+}
+
+func NewOutboxRepository(engine DB) OutboxRepository {
+	return &outboxRepository{engine}
+}
+
+type recordEventArgs struct {
+	Event   string
+	BoardId int64
+	ActorId int64
+	// Data is the board or list the event is about.
+	Data any
+}
+
+// recordEvent adds an event to the outbox. It must be given the session of
+// the transaction making the change, so that the event is saved if and only
+// if the change is. The outbox row keeps the id of that transaction in
+// xact_id, see PublishOutboxEvents.
+func recordEvent(session xorm.Interface, args *recordEventArgs) error {
+	payload, err := json.Marshal(args.Data)
+	if err != nil {
+		return err
+	}
+
+	outboxEvent := &models.OutboxEvent{
+		Event:   args.Event,
+		BoardId: args.BoardId,
+		Payload: string(payload),
+	}
+
+	if args.ActorId != 0 {
+		outboxEvent.ActorId = &args.ActorId
+	}
+
+	_, err = session.Insert(outboxEvent)
+
+	return err
+}
+
+type PublishOutboxEventsArgs struct {
+	Limit int
+	// Publish is given the events in the order they were recorded. They are
+	// marked published when it returns nil and offered again otherwise.
+	Publish func(events []*models.OutboxEvent) error
+}
+
+// PublishOutboxEvents hands the oldest unpublished events to args.Publish and
+// returns how many there were. The events stay locked until they are marked,
+// so concurrent callers publish one after the other.
+//
+// Ids are taken before the transactions recording them commit, so a lower id
+// may become visible after a higher one. Only the events of transactions
+// older than every transaction still running are taken. That set no longer
+// changes, so ordering it by id never publishes an event ahead of one an
+// older transaction is still to commit.
+func (or *outboxRepository) PublishOutboxEvents(args *PublishOutboxEventsArgs) (int, error) {
+	published := 0
+
+	err := inTransaction(or.engine, func(tx DB) error {
+		events := []*models.OutboxEvent{}
+
+		err := tx.SQL(
+			"SELECT * FROM outbox WHERE published_at IS NULL AND xact_id < pg_snapshot_xmin(pg_current_snapshot()) ORDER BY id LIMIT ? FOR UPDATE",
+			args.Limit,
+		).Find(&events)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := args.Publish(events); err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+
+		now := time.Now()
+
+		_, err = tx.
+			In("id", ids).
+			Cols("published_at").
+			Update(&models.OutboxEvent{PublishedAt: &now})
+		if err != nil {
+			return err
+		}
+
+		published = len(events)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
+
+type DeletePublishedOutboxEventsArgs struct {
+	// PublishedBefore keeps events published at or after it.
+	PublishedBefore time.Time
+}
+
+func (or *outboxRepository) DeletePublishedOutboxEvents(args *DeletePublishedOutboxEventsArgs) (int64, error) {
+	return or.engine.
+		Where("published_at < ?", args.PublishedBefore).
+		Delete(new(models.OutboxEvent))
+}
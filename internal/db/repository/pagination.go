@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/pagination"
+	"xorm.io/xorm"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// applyPage adds the filters, keyset condition, order and limit of page to
+// session. Columns are qualified with alias, and one row more than the limit
+// is fetched so that nextPage can tell whether another page follows. Sort
+// and filter fields must have been checked against an allow list by
+// helper.ParsePageQuery.
+func applyPage(session *xorm.Session, alias string, page *pagination.Query) *xorm.Session {
+	column := alias + "." + page.Sort
+	idColumn := alias + ".id"
+
+	for _, filter := range page.Filters {
+		field := alias + "." + filter.Field
+
+		switch filter.Op {
+		case pagination.FilterContains:
+			session = session.And(field+" ILIKE ?", "%"+likeEscaper.Replace(filter.Value)+"%")
+		default:
+			session = session.And(field+" = ?", filter.Value)
+		}
+	}
+
+	order, comparison := "ASC", ">"
+	if page.Desc {
+		order, comparison = "DESC", "<"
+	}
+
+	if page.Cursor != nil {
+		session = session.And(
+			fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", column, idColumn, comparison),
+			page.Cursor.Value, page.Cursor.Value, page.Cursor.Id,
+		)
+	}
+
+	session = session.OrderBy(fmt.Sprintf("%s %s, %s %s", column, order, idColumn, order))
+	if page.Limit > 0 {
+		session = session.Limit(page.Limit + 1)
+	}
+
+	return session
+}
+
+// nextPage trims items fetched with applyPage to the page limit and returns
+// the cursor of the following page, or nil when items is the last page.
+func nextPage[T any](items []T, page *pagination.Query, sortValue func(T) (string, int64)) ([]T, *pagination.Cursor) {
+	if page.Limit == 0 || len(items) <= page.Limit {
+		return items, nil
+	}
+
+	items = items[:page.Limit]
+	value, id := sortValue(items[len(items)-1])
+
+	return items, &pagination.Cursor{
+		Sort:  page.SortParam(),
+		Value: value,
+		Id:    id,
+	}
+}
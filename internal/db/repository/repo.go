@@ -1,21 +1,64 @@
 package repository
 
-import "xorm.io/xorm"
-
 type Repository struct {
 	UserRepository
 	BoardRepository
 	ListRepository
+	SearchRepository
+	WebhookRepository
+	OutboxRepository
+	NotificationRepository
+	DigestRepository
+	WatcherRepository
+	BoardMemberRepository
+	BoardInvitationRepository
+	BoardShareLinkRepository
+
+	db DB
 }
 
-func NewRepository(engine *xorm.Engine) *Repository {
-	userRepository := NewUserRepository(engine)
-	boardRepository := NewBoardRepository(engine)
-	listRepository := NewListRepository(engine)
+func NewRepository(db DB) *Repository {
+	userRepository := NewUserRepository(db)
+	boardRepository := NewBoardRepository(db)
+	listRepository := NewListRepository(db)
+	searchRepository := NewSearchRepository(db)
+	webhookRepository := NewWebhookRepository(db)
+	outboxRepository := NewOutboxRepository(db)
+	notificationRepository := NewNotificationRepository(db)
+	digestRepository := NewDigestRepository(db)
+	watcherRepository := NewWatcherRepository(db)
+	boardMemberRepository := NewBoardMemberRepository(db)
+	boardInvitationRepository := NewBoardInvitationRepository(db)
+	boardShareLinkRepository := NewBoardShareLinkRepository(db)
 
 	return &Repository{
-		UserRepository:  userRepository,
-		BoardRepository: boardRepository,
-		ListRepository:  listRepository,
+		UserRepository:            userRepository,
+		BoardRepository:           boardRepository,
+		ListRepository:            listRepository,
+		SearchRepository:          searchRepository,
+		WebhookRepository:         webhookRepository,
+		OutboxRepository:          outboxRepository,
+		NotificationRepository:    notificationRepository,
+		DigestRepository:          digestRepository,
+		WatcherRepository:         watcherRepository,
+		BoardMemberRepository:     boardMemberRepository,
+		BoardInvitationRepository: boardInvitationRepository,
+		BoardShareLinkRepository:  boardShareLinkRepository,
+		db:                        db,
 	}
 }
+
+// DB returns the handle the repositories query through, for building policies
+// that see the same data.
+func (r *Repository) DB() DB {
+	return r.db
+}
+
+// Transaction runs f with repositories that share a single database
+// transaction. The transaction commits when f returns nil and rolls back
+// otherwise.
+func (r *Repository) Transaction(f func(tx *Repository) error) error {
+	return inTransaction(r.db, func(tx DB) error {
+		return f(NewRepository(tx))
+	})
+}
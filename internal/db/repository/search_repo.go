@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+const (
+	// headlineStart and headlineStop delimit matches in ts_headline output.
+	// Control characters are used so the surrounding text can be HTML
+	// escaped before the markers are turned into <mark> tags.
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var headlineReplacer = strings.NewReplacer(
+	headlineStart, "<mark>",
+	headlineStop, "</mark>",
+)
+
+type SearchRepository interface {
+	Search(args *SearchArgs) ([]*models.SearchHit, error)
+}
+
+type searchRepository struct {
+	engine DB
+}
+
+func NewSearchRepository(engine DB) SearchRepository {
+	return &searchRepository{engine}
+}
+
+type SearchArgs struct {
+	// Query is a to_tsquery expression.
+	Query string
+	// Scope restricts the searched boards. It is an SQL condition on the
+	// board id column "b.id", as returned by policy.BoardPolicy.ViewScope.
+	Scope     string
+	ScopeArgs []any
+	Limit     int
+	Offset    int
+}
+
+func (sr *searchRepository) Search(args *SearchArgs) ([]*models.SearchHit, error) {
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2", headlineStart, headlineStop)
+
+	query := fmt.Sprintf(`
+		SELECT type, id, board_id, name, headline, rank FROM (
+			SELECT 'board' AS type, b.id, b.id AS board_id, b.name,
+				ts_headline('simple', b.name || ' ' || coalesce(b.description, ''), q, '%[1]s') AS headline,
+				ts_rank(b.search_vector, q) AS rank
+			FROM boards b, to_tsquery('simple', ?) q
+			WHERE b.search_vector @@ q AND b.archived_at IS NULL AND %[2]s
+			UNION ALL
+			SELECT 'list' AS type, l.id, l.board_id, l.name,
+				ts_headline('simple', l.name, q, '%[1]s') AS headline,
+				ts_rank(l.search_vector, q) AS rank
+			FROM lists l INNER JOIN boards b ON b.id = l.board_id, to_tsquery('simple', ?) q
+			WHERE l.search_vector @@ q AND l.archived_at IS NULL AND b.archived_at IS NULL AND %[2]s
+		) hits
+		ORDER BY rank DESC, type, id
+		LIMIT ? OFFSET ?`, headlineOptions, args.Scope)
+
+	queryArgs := []any{args.Query}
+	queryArgs = append(queryArgs, args.ScopeArgs...)
+	queryArgs = append(queryArgs, args.Query)
+	queryArgs = append(queryArgs, args.ScopeArgs...)
+	queryArgs = append(queryArgs, args.Limit, args.Offset)
+
+	hits := []*models.SearchHit{}
+
+	err := sr.engine.
+		SQL(query, queryArgs...).
+		Find(&hits)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		hit.Headline = headlineReplacer.Replace(html.EscapeString(hit.Headline))
+	}
+
+	return hits, nil
+}
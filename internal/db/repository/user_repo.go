@@ -3,13 +3,15 @@ package repository
 import (
 	"errors"
 
+	"github.com/lib/pq"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
 	"github.com/mithileshgupta12/velaris/internal/db/models"
-	"xorm.io/xorm"
 )
 
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserCreationFailed = errors.New("failed to create user")
+	ErrUserNotFound       = apperror.New(apperror.KindNotFound, "user_not_found", "user not found")
+	ErrUserCreationFailed = apperror.New(apperror.KindInternal, "user_creation_failed", "failed to create user")
+	ErrUserEmailTaken     = apperror.New(apperror.KindConflict, "email_taken", "email is already taken")
 )
 
 type UserRepository interface {
@@ -19,10 +21,10 @@ type UserRepository interface {
 }
 
 type userRepository struct {
-	engine *xorm.Engine
+	engine DB
 }
 
-func NewUserRepository(engine *xorm.Engine) UserRepository {
+func NewUserRepository(engine DB) UserRepository {
 	return &userRepository{engine}
 }
 
@@ -30,6 +32,7 @@ type CreateUserArgs struct {
 	Name     string
 	Email    string
 	Password string
+	IsGuest  bool
 }
 
 func (ur *userRepository) CreateUser(args *CreateUserArgs) error {
@@ -37,10 +40,16 @@ func (ur *userRepository) CreateUser(args *CreateUserArgs) error {
 		Name:     args.Name,
 		Email:    args.Email,
 		Password: args.Password,
+		IsGuest:  args.IsGuest,
 	}
 
 	affected, err := ur.engine.Insert(user)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "users_email_key" {
+			return ErrUserEmailTaken
+		}
+
 		return err
 	}
 	if affected == 0 {
@@ -0,0 +1,19 @@
+package repository
+
+import "xorm.io/xorm"
+
+// versioned prepares session for updating or deleting a row with a version
+// column. Updates always increment the version. When expectedVersion is not
+// zero the statement only matches the row while it is still at that version,
+// which is how concurrent writers are detected.
+func versioned(session *xorm.Session, expectedVersion int) *xorm.Session {
+	session = session.
+		NoVersionCheck().
+		Incr("version")
+
+	if expectedVersion != 0 {
+		session = session.And("version = ?", expectedVersion)
+	}
+
+	return session
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+type WatcherRepository interface {
+	WatchBoard(args *WatchBoardArgs) error
+	UnwatchBoard(args *UnwatchBoardArgs) error
+	WatchList(args *WatchListArgs) error
+	UnwatchList(args *UnwatchListArgs) error
+}
+
+type watcherRepository struct {
+	engine DB
+}
+
+func NewWatcherRepository(engine DB) WatcherRepository {
+	return &watcherRepository{engine}
+}
+
+type WatchBoardArgs struct {
+	UserId  int64
+	BoardId int64
+}
+
+// WatchBoard makes the user watch every event of the board. Watching a board
+// already watched changes nothing.
+func (wr *watcherRepository) WatchBoard(args *WatchBoardArgs) error {
+	_, err := wr.engine.Exec(`
+		INSERT INTO watchers (user_id, board_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		args.UserId, args.BoardId, time.Now(),
+	)
+
+	return err
+}
+
+type UnwatchBoardArgs struct {
+	UserId  int64
+	BoardId int64
+}
+
+// UnwatchBoard stops the user watching the board. The lists of the board they
+// watch on their own are still watched.
+func (wr *watcherRepository) UnwatchBoard(args *UnwatchBoardArgs) error {
+	_, err := wr.engine.
+		Where("user_id = ? AND board_id = ? AND list_id IS NULL", args.UserId, args.BoardId).
+		Delete(new(models.Watcher))
+
+	return err
+}
+
+type WatchListArgs struct {
+	UserId  int64
+	BoardId int64
+	ListId  int64
+}
+
+// WatchList makes the user watch the events of one list. Watching a list
+// already watched changes nothing.
+func (wr *watcherRepository) WatchList(args *WatchListArgs) error {
+	_, err := wr.engine.Exec(`
+		INSERT INTO watchers (user_id, board_id, list_id, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		args.UserId, args.BoardId, args.ListId, time.Now(),
+	)
+
+	return err
+}
+
+type UnwatchListArgs struct {
+	UserId int64
+	ListId int64
+}
+
+func (wr *watcherRepository) UnwatchList(args *UnwatchListArgs) error {
+	_, err := wr.engine.
+		Where("user_id = ? AND list_id = ?", args.UserId, args.ListId).
+		Delete(new(models.Watcher))
+
+	return err
+}
@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/pagination"
+	"xorm.io/xorm"
+)
+
+var ErrWebhookNotFound = apperror.New(apperror.KindNotFound, "webhook_not_found", "webhook not found")
+
+type WebhookRepository interface {
+	GetAllWebhooksByBoardId(args *GetAllWebhooksByBoardIdArgs) ([]*models.Webhook, error)
+	CreateWebhook(args *CreateWebhookArgs) (*models.Webhook, error)
+	GetWebhookById(args *GetWebhookByIdArgs) (*models.Webhook, error)
+	DeleteWebhookById(args *DeleteWebhookByIdArgs) error
+	GetAllWebhookDeliveriesByWebhookId(args *GetAllWebhookDeliveriesByWebhookIdArgs) ([]*models.WebhookDelivery, *pagination.Cursor, error)
+	EnqueueWebhookDeliveries(args *EnqueueWebhookDeliveriesArgs) error
+	ClaimWebhookDeliveries(args *ClaimWebhookDeliveriesArgs) ([]*models.WebhookDelivery, error)
+	RecordWebhookDeliveryAttempt(args *RecordWebhookDeliveryAttemptArgs) error
+}
+
+type webhookRepository struct {
+	engine DB
+}
+
+func NewWebhookRepository(engine DB) WebhookRepository {
+	return &webhookRepository{engine}
+}
+
+type GetAllWebhooksByBoardIdArgs struct {
+	BoardId int64
+}
+
+func (wr *webhookRepository) GetAllWebhooksByBoardId(args *GetAllWebhooksByBoardIdArgs) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+
+	err := wr.engine.
+		Where("board_id = ?", args.BoardId).
+		OrderBy("id").
+		Find(&webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+type CreateWebhookArgs struct {
+	BoardId int64
+	Url     string
+	Secret  string
+	Events  []string
+}
+
+func (wr *webhookRepository) CreateWebhook(args *CreateWebhookArgs) (*models.Webhook, error) {
+	webhook := &models.Webhook{
+		BoardId: args.BoardId,
+		Url:     args.Url,
+		Secret:  args.Secret,
+		Events:  args.Events,
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+
+	if _, err := wr.engine.Insert(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+type GetWebhookByIdArgs struct {
+	Id      int64
+	BoardId int64
+}
+
+func (wr *webhookRepository) GetWebhookById(args *GetWebhookByIdArgs) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+
+	has, err := wr.engine.
+		Where("id = ? AND board_id = ?", args.Id, args.BoardId).
+		Get(webhook)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+type DeleteWebhookByIdArgs struct {
+	Id      int64
+	BoardId int64
+}
+
+func (wr *webhookRepository) DeleteWebhookById(args *DeleteWebhookByIdArgs) error {
+	affected, err := wr.engine.
+		Where("id = ? AND board_id = ?", args.Id, args.BoardId).
+		Delete(new(models.Webhook))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+type GetAllWebhookDeliveriesByWebhookIdArgs struct {
+	WebhookId int64
+	// Page limits and orders the deliveries, newest first by default.
+	Page *pagination.Query
+}
+
+// GetAllWebhookDeliveriesByWebhookId returns deliveries with their attempts
+// loaded.
+func (wr *webhookRepository) GetAllWebhookDeliveriesByWebhookId(args *GetAllWebhookDeliveriesByWebhookIdArgs) ([]*models.WebhookDelivery, *pagination.Cursor, error) {
+	deliveries := []*models.WebhookDelivery{}
+
+	query := wr.engine.
+		Alias("d").
+		Where("d.webhook_id = ?", args.WebhookId)
+
+	if args.Page != nil {
+		query = applyPage(query, "d", args.Page)
+	} else {
+		query = query.OrderBy("d.id DESC")
+	}
+
+	if err := query.Find(&deliveries); err != nil {
+		return nil, nil, err
+	}
+
+	var next *pagination.Cursor
+	if args.Page != nil {
+		deliveries, next = nextPage(deliveries, args.Page, func(delivery *models.WebhookDelivery) (string, int64) {
+			return delivery.CreatedAt.Format(time.RFC3339Nano), delivery.Id
+		})
+	}
+
+	if err := loadWebhookDeliveryAttempts(wr.engine, deliveries); err != nil {
+		return nil, nil, err
+	}
+
+	return deliveries, next, nil
+}
+
+// loadWebhookDeliveryAttempts fills Attempts with the attempts of each
+// delivery, oldest first.
+func loadWebhookDeliveryAttempts(engine DB, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	deliveryIds := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryIds = append(deliveryIds, delivery.Id)
+	}
+
+	attempts := []*models.WebhookDeliveryAttempt{}
+
+	err := engine.
+		In("delivery_id", deliveryIds).
+		OrderBy("id").
+		Find(&attempts)
+	if err != nil {
+		return err
+	}
+
+	attemptsByDeliveryId := make(map[int64][]*models.WebhookDeliveryAttempt, len(deliveries))
+	for _, attempt := range attempts {
+		attemptsByDeliveryId[attempt.DeliveryId] = append(attemptsByDeliveryId[attempt.DeliveryId], attempt)
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Attempts = attemptsByDeliveryId[delivery.Id]
+		if delivery.Attempts == nil {
+			delivery.Attempts = []*models.WebhookDeliveryAttempt{}
+		}
+	}
+
+	return nil
+}
+
+type EnqueueWebhookDeliveriesArgs struct {
+	BoardId int64
+	// EventId is the outbox id of the event. A webhook gets one delivery
+	// per event however often the event is enqueued.
+	EventId int64
+	Event   string
+	// Payload is the JSON body sent to every webhook of the board that
+	// subscribes to Event.
+	Payload string
+}
+
+// EnqueueWebhookDeliveries queues a delivery of the event for each webhook of
+// the board that subscribes to it. The deliveries are due immediately.
+func (wr *webhookRepository) EnqueueWebhookDeliveries(args *EnqueueWebhookDeliveriesArgs) error {
+	webhooks, err := wr.GetAllWebhooksByBoardId(&GetAllWebhooksByBoardIdArgs{BoardId: args.BoardId})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, webhook := range webhooks {
+		if !subscribes(webhook, args.Event) {
+			continue
+		}
+
+		_, err := wr.engine.Exec(`
+			INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, status, next_attempt_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (webhook_id, event_id) DO NOTHING`,
+			webhook.Id, args.EventId, args.Event, args.Payload, models.WebhookDeliveryPending, now, now, now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func subscribes(webhook *models.Webhook, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for _, subscribed := range webhook.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+type ClaimWebhookDeliveriesArgs struct {
+	Limit int
+	// Lease is how long the claimed deliveries are hidden from other
+	// claims. A delivery whose attempt is not recorded within the lease, for
+	// instance because the process died, is claimed again afterwards.
+	Lease time.Duration
+}
+
+// ClaimWebhookDeliveries returns pending deliveries that are due, with their
+// webhook loaded, and pushes their next attempt back by the lease so that
+// concurrent dispatchers do not send them twice.
+func (wr *webhookRepository) ClaimWebhookDeliveries(args *ClaimWebhookDeliveriesArgs) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	now := time.Now()
+
+	err := wr.engine.SQL(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(args.Lease), now, models.WebhookDeliveryPending, now, args.Limit,
+	).Find(&deliveries)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	webhookIds := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		webhookIds = append(webhookIds, delivery.WebhookId)
+	}
+
+	webhooks := []*models.Webhook{}
+	if err := wr.engine.In("id", webhookIds).Find(&webhooks); err != nil {
+		return nil, err
+	}
+
+	webhooksById := make(map[int64]*models.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhooksById[webhook.Id] = webhook
+	}
+
+	for _, delivery := range deliveries {
+		delivery.Webhook = webhooksById[delivery.WebhookId]
+	}
+
+	return deliveries, nil
+}
+
+type RecordWebhookDeliveryAttemptArgs struct {
+	DeliveryId int64
+	// StatusCode is nil when the request failed before a response arrived,
+	// in which case Error says why.
+	StatusCode *int
+	Error      *string
+	Duration   time.Duration
+	// Status is the status of the delivery after the attempt.
+	Status string
+	// NextAttemptAt is when a pending delivery is tried again.
+	NextAttemptAt *time.Time
+}
+
+func (wr *webhookRepository) RecordWebhookDeliveryAttempt(args *RecordWebhookDeliveryAttemptArgs) error {
+	_, err := wr.engine.Transaction(func(session *xorm.Session) (any, error) {
+		attempt := &models.WebhookDeliveryAttempt{
+			DeliveryId: args.DeliveryId,
+			StatusCode: args.StatusCode,
+			Error:      args.Error,
+			DurationMs: int(args.Duration.Milliseconds()),
+		}
+
+		if _, err := session.Insert(attempt); err != nil {
+			return nil, err
+		}
+
+		delivery := &models.WebhookDelivery{
+			Status:         args.Status,
+			NextAttemptAt:  args.NextAttemptAt,
+			LastStatusCode: args.StatusCode,
+		}
+
+		if args.Status == models.WebhookDeliverySucceeded {
+			delivery.DeliveredAt = &attempt.CreatedAt
+		}
+
+		_, err := session.
+			ID(args.DeliveryId).
+			Incr("attempt_count").
+			Cols("status", "next_attempt_at", "last_status_code", "delivered_at").
+			Update(delivery)
+
+		return nil, err
+	})
+
+	return err
+}
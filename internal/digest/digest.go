@@ -0,0 +1,269 @@
+// Package digest emails users the notifications they have not read, batched
+// at the frequency they chose, with a link to unsubscribe that works without
+// a session.
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/event"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+	"github.com/mithileshgupta12/velaris/internal/mail"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+// UnsubscribePurpose is the purpose of the signed tokens in unsubscribe
+// links, whose subject is the user id.
+const UnsubscribePurpose = "unsubscribe"
+
+// maxItems caps the notifications in one email. The rest go in the next one.
+const maxItems = 100
+
+var (
+	//go:embed templates
+	templates embed.FS
+
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templates, "templates/*.txt"))
+)
+
+// schedules are when the digests of each frequency are sent, in UTC.
+var schedules = map[string]string{
+	models.DigestImmediate: "* * * * *",
+	models.DigestHourly:    "@hourly",
+	models.DigestDaily:     "0 7 * * *",
+}
+
+var frequencyText = map[string]string{
+	models.DigestImmediate: "as activity happens",
+	models.DigestHourly:    "hourly",
+	models.DigestDaily:     "daily",
+}
+
+type Digester struct {
+	digestRepository repository.DigestRepository
+	userRepository   repository.UserRepository
+	boardPolicy      policy.BoardPolicy
+	mailer           mail.Mailer
+	appKey           string
+	apiUrl           string
+	frontendUrl      string
+}
+
+func NewDigester(
+	digestRepository repository.DigestRepository,
+	userRepository repository.UserRepository,
+	boardPolicy policy.BoardPolicy,
+	mailer mail.Mailer,
+	appKey string,
+	apiUrl string,
+	frontendUrl string,
+) *Digester {
+	return &Digester{digestRepository, userRepository, boardPolicy, mailer, appKey, apiUrl, frontendUrl}
+}
+
+type enqueueArgs struct {
+	Frequency string `json:"frequency"`
+}
+
+type sendArgs struct {
+	UserId int64 `json:"user_id"`
+}
+
+// Register adds the digest jobs to queue: one per frequency, on its
+// schedule, enqueueing a job that sends the digest of each user due one.
+func (d *Digester) Register(queue *jobs.Queue) {
+	sendJob := jobs.Register(queue, "digests.send", jobs.Options{}, func(ctx context.Context, args sendArgs) error {
+		return d.Send(ctx, args.UserId)
+	})
+
+	enqueueJob := jobs.Register(queue, "digests.enqueue", jobs.Options{}, func(ctx context.Context, args enqueueArgs) error {
+		userIds, err := d.digestRepository.GetDigestUserIds(args.Frequency)
+		if err != nil {
+			return err
+		}
+
+		for _, userId := range userIds {
+			_, err := sendJob.Enqueue(ctx, sendArgs{UserId: userId}, &jobs.EnqueueOptions{
+				UniqueKey: strconv.FormatInt(userId, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	for frequency, spec := range schedules {
+		enqueueJob.Schedule(spec, enqueueArgs{Frequency: frequency})
+	}
+}
+
+type digestItem struct {
+	Text       string
+	Url        string
+	OccurredAt time.Time
+}
+
+type digestData struct {
+	Subject        string
+	Name           string
+	Frequency      string
+	Items          []*digestItem
+	AppUrl         string
+	UnsubscribeUrl string
+}
+
+// Send emails the user the notifications not sent yet, if there are any and
+// they did not turn digests off.
+func (d *Digester) Send(ctx context.Context, userId int64) error {
+	setting, err := d.digestRepository.GetDigestSettingByUserId(userId)
+	if err != nil {
+		return err
+	}
+	if setting.Frequency == models.DigestOff {
+		return nil
+	}
+
+	user, err := d.userRepository.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+
+	scope, scopeArgs := d.boardPolicy.ViewScope(middleware.CtxUser{ID: user.Id}, "n.board_id")
+
+	notifications, err := d.digestRepository.GetDigestNotificationsByUserId(&repository.GetDigestNotificationsByUserIdArgs{
+		UserId:    user.Id,
+		Scope:     scope,
+		ScopeArgs: scopeArgs,
+		Limit:     maxItems,
+	})
+	if err != nil {
+		return err
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	unsubscribeUrl := d.UnsubscribeUrl(user.Id)
+
+	data := &digestData{
+		Name:           user.Name,
+		Frequency:      frequencyText[setting.Frequency],
+		AppUrl:         d.frontendUrl,
+		UnsubscribeUrl: unsubscribeUrl,
+	}
+	for _, notification := range notifications {
+		data.Items = append(data.Items, &digestItem{
+			Text:       describe(notification),
+			Url:        fmt.Sprintf("%s/boards/%d", d.frontendUrl, notification.BoardId),
+			OccurredAt: notification.CreatedAt.UTC(),
+		})
+	}
+
+	if len(data.Items) == 1 {
+		data.Subject = "1 update on your Velaris boards"
+	} else {
+		data.Subject = fmt.Sprintf("%d updates on your Velaris boards", len(data.Items))
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, "digest.html", data); err != nil {
+		return err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, "digest.txt", data); err != nil {
+		return err
+	}
+
+	err = d.mailer.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			// One-click unsubscribe from the mail client, as in RFC 8058.
+			"List-Unsubscribe":      "<" + unsubscribeUrl + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.digestRepository.MarkDigestSent(&repository.MarkDigestSentArgs{
+		UserId:             user.Id,
+		LastNotificationId: notifications[len(notifications)-1].Id,
+	})
+}
+
+// UnsubscribeUrl returns the link turning the user's digests off.
+func (d *Digester) UnsubscribeUrl(userId int64) string {
+	token := helper.SignToken(d.appKey, UnsubscribePurpose, strconv.FormatInt(userId, 10), time.Time{})
+
+	return d.apiUrl + "/v1/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// RenderUnsubscribe writes the page asking to confirm an unsubscription,
+// whose form posts token back.
+func RenderUnsubscribe(w io.Writer, appUrl, token string) error {
+	return htmlTemplates.ExecuteTemplate(w, "unsubscribe.html", map[string]string{"AppUrl": appUrl, "Token": token})
+}
+
+// RenderUnsubscribed writes the page confirming an unsubscription.
+func RenderUnsubscribed(w io.Writer, appUrl string) error {
+	return htmlTemplates.ExecuteTemplate(w, "unsubscribed.html", map[string]string{"AppUrl": appUrl})
+}
+
+// describe tells what happened in a sentence, e.g. `Jane archived the list
+// "Done" on Roadmap`.
+func describe(notification *models.DigestNotification) string {
+	actor := "Someone"
+	if notification.ActorName != nil {
+		actor = *notification.ActorName
+	}
+
+	board := "a board"
+	if notification.BoardName != nil {
+		board = *notification.BoardName
+	}
+
+	list := "a list"
+	if name, ok := notification.Data["name"].(string); ok {
+		list = fmt.Sprintf("%q", name)
+	}
+
+	switch notification.Event {
+	case event.BoardUpdated:
+		return fmt.Sprintf("%s updated %s", actor, board)
+	case event.BoardArchived:
+		return fmt.Sprintf("%s archived %s", actor, board)
+	case event.BoardUnarchived:
+		return fmt.Sprintf("%s restored %s", actor, board)
+	case event.ListCreated:
+		return fmt.Sprintf("%s added the list %s to %s", actor, list, board)
+	case event.ListUpdated:
+		return fmt.Sprintf("%s updated the list %s on %s", actor, list, board)
+	case event.ListDeleted:
+		return fmt.Sprintf("%s deleted %s from %s", actor, list, board)
+	case event.ListArchived:
+		return fmt.Sprintf("%s archived the list %s on %s", actor, list, board)
+	case event.ListUnarchived:
+		return fmt.Sprintf("%s restored the list %s on %s", actor, list, board)
+	default:
+		return fmt.Sprintf("%s changed %s", actor, board)
+	}
+}
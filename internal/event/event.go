@@ -0,0 +1,36 @@
+// Package event names the domain events recorded in the outbox and describes
+// them as they are published to subscribers.
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	BoardCreated    = "board.created"
+	BoardUpdated    = "board.updated"
+	BoardDeleted    = "board.deleted"
+	BoardArchived   = "board.archived"
+	BoardUnarchived = "board.unarchived"
+	ListCreated     = "list.created"
+	ListUpdated     = "list.updated"
+	ListDeleted     = "list.deleted"
+	ListArchived    = "list.archived"
+	ListUnarchived  = "list.unarchived"
+)
+
+// Event is a domain event as published on the event stream.
+type Event struct {
+	// Id is the id of the outbox row. Events are delivered at least once,
+	// so subscribers use it to recognise redeliveries.
+	Id      int64  `json:"id"`
+	Name    string `json:"name"`
+	BoardId int64  `json:"board_id"`
+	// ActorId is the user whose request caused the event, zero when there
+	// was none.
+	ActorId int64 `json:"actor_id,omitempty"`
+	// Data is the board or list the event is about, as the API renders it.
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
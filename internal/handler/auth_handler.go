@@ -7,15 +7,16 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"net/mail"
 	"strings"
 	"time"
 
-	"github.com/lib/pq"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
 	"github.com/mithileshgupta12/velaris/internal/cache"
 	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/invitation"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
 )
 
 type RegisterUserRequest struct {
@@ -23,6 +24,9 @@ type RegisterUserRequest struct {
 	Email                string `json:"email"`
 	Password             string `json:"password"`
 	PasswordConfirmation string `json:"password_confirmation"`
+	// InvitationToken, when set, accepts the board invitation whose link
+	// led to the registration.
+	InvitationToken string `json:"invitation_token"`
 }
 
 type LoginUserRequest struct {
@@ -30,13 +34,24 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+// errInvalidCredentials is returned for an unknown email and a wrong password
+// alike, so the response does not reveal which accounts exist.
+var errInvalidCredentials = apperror.New(apperror.KindInvalid, "invalid_credentials", "username or password is invalid")
+
 type AuthHandler struct {
-	userRepository repository.UserRepository
-	sessionStore   cache.SessionStore
+	// repositories is needed whole to register users and accept their
+	// invitation in one transaction.
+	repositories *repository.Repository
+	sessionStore cache.SessionStore
+	appKey       string
 }
 
-func NewAuthHandler(userRepository repository.UserRepository, sessionStore cache.SessionStore) *AuthHandler {
-	return &AuthHandler{userRepository, sessionStore}
+func NewAuthHandler(
+	repositories *repository.Repository,
+	sessionStore cache.SessionStore,
+	appKey string,
+) *AuthHandler {
+	return &AuthHandler{repositories, sessionStore, appKey}
 }
 
 func (ah *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -44,88 +59,90 @@ func (ah *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&registerUserRequest); err != nil {
 		slog.Error("failed to decode request", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid request")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	registerUserRequest.Name = strings.TrimSpace(registerUserRequest.Name)
 	registerUserRequest.Email = strings.ToLower(strings.TrimSpace(registerUserRequest.Email))
 
-	if registerUserRequest.Name == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "name is a required field")
-		return
-	}
-
-	if len(registerUserRequest.Name) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "name must not be more than 255 characters long")
-		return
-	}
-
-	if registerUserRequest.Email == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "email is a required field")
-		return
-	}
-
-	if len(registerUserRequest.Email) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "email must not be more than 255 characters long")
-		return
-	}
+	v := validation.New()
+
+	v.String("name", registerUserRequest.Name, validation.Required(), validation.MaxLength(255))
+	v.String("email", registerUserRequest.Email, validation.Required(), validation.MaxLength(255), validation.Email())
+	v.String("password", registerUserRequest.Password, validation.Required(), validation.MinLength(8), validation.MaxLength(255))
+	v.String(
+		"password_confirmation",
+		registerUserRequest.PasswordConfirmation,
+		validation.Required(),
+		validation.MaxLength(255),
+		validation.Equals("password", registerUserRequest.Password),
+	)
+
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	// The invitation is checked first so that a stale link fails the
+	// registration instead of creating an account without the board. Guest
+	// invitations create guest accounts.
+	var invitationId int64
+	var isGuest bool
+	if registerUserRequest.InvitationToken != "" {
+		id, err := invitation.ParseToken(ah.appKey, registerUserRequest.InvitationToken)
+		if err != nil {
+			helper.AppErrorJsonResponse(w, r, err)
+			return
+		}
 
-	if registerUserRequest.Password == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password is a required field")
-		return
-	}
+		boardInvitation, err := ah.repositories.GetBoardInvitationById(id)
+		if err != nil {
+			helper.AppErrorJsonResponse(w, r, err)
+			return
+		}
 
-	if len(registerUserRequest.Password) < 8 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password must be at least 8 characters long")
-		return
+		invitationId = id
+		isGuest = boardInvitation.Guest
 	}
 
-	if len(registerUserRequest.Password) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password must not be more than 255 characters long")
+	hashedPassword, err := helper.HashPassword(registerUserRequest.Password)
+	if err != nil {
+		slog.Error("failed to hash password", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	if registerUserRequest.PasswordConfirmation == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password_confirmation is a required field")
-		return
-	}
+	// The account is only kept if the invitation is accepted too, so that a
+	// registration failing on an invitation revoked meanwhile can be retried.
+	err = ah.repositories.Transaction(func(tx *repository.Repository) error {
+		if err := tx.CreateUser(&repository.CreateUserArgs{
+			Name:     registerUserRequest.Name,
+			Email:    registerUserRequest.Email,
+			Password: hashedPassword,
+			IsGuest:  isGuest,
+		}); err != nil {
+			return err
+		}
 
-	if len(registerUserRequest.PasswordConfirmation) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password_confirmation must not be more than 255 characters long")
-		return
-	}
+		if invitationId == 0 {
+			return nil
+		}
 
-	if _, err := mail.ParseAddress(registerUserRequest.Email); err != nil {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "email must be a valid email")
-		return
-	}
+		user, err := tx.GetUserByEmail(registerUserRequest.Email)
+		if err != nil {
+			return err
+		}
 
-	if registerUserRequest.Password != registerUserRequest.PasswordConfirmation {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password and password_confirmation do not match")
-		return
-	}
+		_, err = tx.AcceptBoardInvitation(&repository.AcceptBoardInvitationArgs{
+			Id:     invitationId,
+			UserId: user.Id,
+		})
 
-	hashedPassword, err := helper.HashPassword(registerUserRequest.Password)
+		return err
+	})
 	if err != nil {
-		slog.Error("failed to hash password", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
-		return
-	}
-
-	if err := ah.userRepository.CreateUser(&repository.CreateUserArgs{
-		Name:     registerUserRequest.Name,
-		Email:    registerUserRequest.Email,
-		Password: hashedPassword,
-	}); err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			if pqErr.Code == "23505" && pqErr.Constraint == "users_email_key" {
-				helper.ErrorJsonResponse(w, http.StatusConflict, "email is already taken")
-				return
-			}
-		}
-		slog.Error("failed to register user", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.AppErrorJsonResponse(w, r, err)
 		return
 	}
 
@@ -137,47 +154,37 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&loginUserRequest); err != nil {
 		slog.Error("failed to decode request", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid request")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	loginUserRequest.Email = strings.ToLower(strings.TrimSpace(loginUserRequest.Email))
 
-	if loginUserRequest.Email == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "email is a required field")
-		return
-	}
+	v := validation.New()
 
-	if len(loginUserRequest.Email) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "email must not be more than 255 characters long")
-		return
-	}
-
-	if loginUserRequest.Password == "" {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password is a required field")
-		return
-	}
+	v.String("email", loginUserRequest.Email, validation.Required(), validation.MaxLength(255))
+	v.String("password", loginUserRequest.Password, validation.Required(), validation.MaxLength(255))
 
-	if len(loginUserRequest.Password) > 255 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "password must not be more than 255 characters long")
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
 		return
 	}
 
-	user, err := ah.userRepository.GetUserByEmail(loginUserRequest.Email)
+	user, err := ah.repositories.GetUserByEmail(loginUserRequest.Email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			helper.ErrorJsonResponse(w, http.StatusBadRequest, "username or password is invalid")
+			helper.AppErrorJsonResponse(w, r, errInvalidCredentials)
 			return
 		}
 
 		slog.Error("failed to get user by email", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	ok, err := helper.VerifyPassword(loginUserRequest.Password, user.Password)
 	if err != nil || !ok {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "username or password is invalid")
+		helper.AppErrorJsonResponse(w, r, errInvalidCredentials)
 		return
 	}
 
@@ -185,7 +192,7 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	_, err = rand.Read(sessionID)
 	if err != nil {
 		slog.Error("failed to create session ID", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -193,7 +200,7 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	if err := ah.sessionStore.Set(r.Context(), b64SessionID, user.Id, time.Duration(time.Hour*24)); err != nil {
 		slog.Error("failed to set value in session store", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -204,6 +211,7 @@ func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		ID:        user.Id,
 		Name:      user.Name,
 		Email:     user.Email,
+		IsGuest:   user.IsGuest,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -215,13 +223,13 @@ func (ah *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	sessionCookie, err := r.Cookie("auth_session")
 	if err != nil {
 		slog.Error("failed to get session cookie", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusUnauthorized, "Unautenticated")
+		helper.ErrorJsonResponse(w, r, http.StatusUnauthorized, "unauthenticated")
 		return
 	}
 
 	if err := ah.sessionStore.Del(r.Context(), sessionCookie.Value); err != nil {
 		slog.Error("failed to delete record from session", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+)
+
+// maxBatchOperations caps the number of sub-requests in one batch.
+const maxBatchOperations = 20
+
+type BatchOperation struct {
+	Method string `json:"method"`
+	// Path is the path and query of the sub-request, e.g. "/v1/boards/1".
+	Path string `json:"path"`
+	// Headers may set Accept, Content-Type, If-Match and If-None-Match. The
+	// session and the other headers come from the batch request.
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type BatchRequest struct {
+	// Atomic runs the operations in a single transaction that is rolled back
+	// as soon as one of them fails.
+	Atomic     bool              `json:"atomic"`
+	Operations []*BatchOperation `json:"operations"`
+}
+
+type BatchResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the JSON response of the operation, or a string holding any
+	// other response.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+type BatchResponse struct {
+	// RolledBack is set when an atomic batch failed. Results then end with
+	// the operation that failed and nothing before it was saved.
+	RolledBack bool           `json:"rolled_back"`
+	Results    []*BatchResult `json:"results"`
+}
+
+// Transactor calls run with a handler serving the API inside a single
+// database transaction. The transaction commits when run returns nil and
+// rolls back otherwise.
+type Transactor func(run func(api http.Handler) error) error
+
+var (
+	batchMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	// batchRequestHeaders are the headers an operation may set itself.
+	batchRequestHeaders = []string{"Accept", "Content-Type", "If-Match", "If-None-Match"}
+	// batchSharedHeaders are copied from the batch request to every operation.
+	batchSharedHeaders = []string{"Accept", "Authorization", "Cookie"}
+	// batchResultHeaders are the response headers reported per operation.
+	batchResultHeaders = []string{"Deprecation", "ETag", "Link", "Location", "Sunset"}
+
+	errBatchOperationFailed = errors.New("batch operation failed")
+	errBatchNested          = apperror.New(apperror.KindInvalid, "batch_nested", "a batch operation must not be a batch")
+)
+
+type batchOperationKey string
+
+// ctxBatchOperationKey marks the context of batch sub-requests, whatever the
+// API version they were routed through, so that Run refuses to nest.
+const ctxBatchOperationKey batchOperationKey = "batchOperation"
+
+type BatchHandler struct {
+	api      http.Handler
+	transact Transactor
+}
+
+// NewBatchHandler returns a handler dispatching operations to api, or to the
+// handler transact provides for atomic batches.
+func NewBatchHandler(api http.Handler, transact Transactor) *BatchHandler {
+	return &BatchHandler{api, transact}
+}
+
+func (bh *BatchHandler) validateBatchRequest(batchRequest *BatchRequest) error {
+	v := validation.New()
+
+	v.Check("operations", len(batchRequest.Operations) > 0, validation.CodeRequired, "operations is a required field")
+	v.Check(
+		"operations",
+		len(batchRequest.Operations) <= maxBatchOperations,
+		validation.CodeTooLarge,
+		fmt.Sprintf("operations must not have more than %d items", maxBatchOperations),
+	)
+
+	for i, operation := range batchRequest.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+
+		if operation == nil {
+			v.Add(field, validation.CodeRequired, fmt.Sprintf("%s is a required field", field))
+			continue
+		}
+
+		operation.Method = strings.ToUpper(operation.Method)
+		v.String(field+".method", operation.Method, validation.Required(), validation.OneOf(batchMethods...))
+
+		v.String(field+".path", operation.Path, validation.Required())
+		if operation.Path != "" {
+			path, err := url.Parse(operation.Path)
+			v.Check(
+				field+".path",
+				err == nil && path.Scheme == "" && path.Host == "" && strings.HasPrefix(path.Path, "/"),
+				validation.CodeInvalid,
+				fmt.Sprintf("%s.path must be an absolute path", field),
+			)
+			v.Check(
+				field+".path",
+				err != nil || !isStreamPath(path.Path),
+				validation.CodeNotAllowed,
+				fmt.Sprintf("%s.path must not be a stream", field),
+			)
+		}
+
+		for name := range operation.Headers {
+			v.Check(
+				field+".headers",
+				containsHeader(batchRequestHeaders, name),
+				validation.CodeNotAllowed,
+				fmt.Sprintf("%s.headers must only contain %s", field, strings.Join(batchRequestHeaders, ", ")),
+			)
+		}
+	}
+
+	return v.Err()
+}
+
+func (bh *BatchHandler) Run(w http.ResponseWriter, r *http.Request) {
+	if r.Context().Value(ctxBatchOperationKey) != nil {
+		helper.AppErrorJsonResponse(w, r, errBatchNested)
+		return
+	}
+
+	var batchRequest BatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&batchRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if err := bh.validateBatchRequest(&batchRequest); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	batchResponse := &BatchResponse{Results: []*BatchResult{}}
+
+	if !batchRequest.Atomic {
+		for _, operation := range batchRequest.Operations {
+			batchResponse.Results = append(batchResponse.Results, bh.dispatch(bh.api, r, operation))
+		}
+
+		helper.JsonResponse(w, http.StatusOK, batchResponse)
+		return
+	}
+
+	err := bh.transact(func(api http.Handler) error {
+		for _, operation := range batchRequest.Operations {
+			result := bh.dispatch(api, r, operation)
+			batchResponse.Results = append(batchResponse.Results, result)
+
+			if result.Status >= http.StatusBadRequest {
+				return errBatchOperationFailed
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errBatchOperationFailed) {
+			slog.Error("failed to run batch", "err", err)
+			helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		batchResponse.RolledBack = true
+	}
+
+	helper.JsonResponse(w, http.StatusOK, batchResponse)
+}
+
+// dispatch serves operation with api as if the caller had sent it directly,
+// and captures the response.
+func (bh *BatchHandler) dispatch(api http.Handler, r *http.Request, operation *BatchOperation) *BatchResult {
+	// The batch request's routing context would make the router treat the
+	// sub-request as already routed.
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, nil)
+	ctx = context.WithValue(ctx, ctxBatchOperationKey, true)
+
+	subRequest, err := http.NewRequestWithContext(ctx, operation.Method, operation.Path, bytes.NewReader(operation.Body))
+	if err != nil {
+		slog.Error("failed to create batch sub-request", "err", err)
+		return &BatchResult{Status: http.StatusInternalServerError}
+	}
+
+	subRequest.RequestURI = operation.Path
+	subRequest.RemoteAddr = r.RemoteAddr
+
+	for _, name := range batchSharedHeaders {
+		if values := r.Header.Values(name); len(values) > 0 {
+			subRequest.Header[name] = values
+		}
+	}
+	if len(operation.Body) > 0 {
+		subRequest.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range operation.Headers {
+		subRequest.Header.Set(name, value)
+	}
+
+	rw := &batchResponseWriter{header: http.Header{}}
+	api.ServeHTTP(rw, subRequest)
+
+	result := &BatchResult{Status: rw.statusCode}
+	if result.Status == 0 {
+		result.Status = http.StatusOK
+	}
+
+	for _, name := range batchResultHeaders {
+		if value := rw.header.Get(name); value != "" {
+			if result.Headers == nil {
+				result.Headers = map[string]string{}
+			}
+			result.Headers[name] = value
+		}
+	}
+
+	body := bytes.TrimSpace(rw.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		result.Body = body
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+
+	return result
+}
+
+// isStreamPath reports whether path serves a stream, whose response never
+// ends.
+func isStreamPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), "/stream")
+}
+
+func containsHeader(headers []string, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// batchResponseWriter keeps the response of a sub-request in memory.
+type batchResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *batchResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *batchResponseWriter) WriteHeader(statusCode int) {
+	if rw.statusCode != 0 {
+		return
+	}
+
+	rw.statusCode = statusCode
+}
+
+func (rw *batchResponseWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	return rw.body.Write(b)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// runBatch runs body through a batch handler whose operations must never be
+// dispatched and returns the response.
+func runBatch(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("operation %s %s was dispatched", r.Method, r.URL.Path)
+	})
+	transact := func(run func(api http.Handler) error) error {
+		return run(api)
+	}
+
+	rec := httptest.NewRecorder()
+	NewBatchHandler(api, transact).Run(rec, httptest.NewRequest(http.MethodPost, "/v1/batch", strings.NewReader(body)))
+
+	return rec
+}
+
+func TestBatchRejectsStreams(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		body, _ := json.Marshal(map[string]any{
+			"atomic": atomic,
+			"operations": []map[string]string{
+				{"method": "GET", "path": "/v1/notifications/stream"},
+			},
+		})
+
+		rec := runBatch(t, string(body))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("atomic %v: status = %d, want %d", atomic, rec.Code, http.StatusBadRequest)
+		}
+		if !strings.Contains(rec.Body.String(), `"operations[0].path":["not_allowed"]`) {
+			t.Errorf("atomic %v: body = %s, want a not_allowed path", atomic, rec.Body.String())
+		}
+	}
+}
+
+func TestBatchRefusesNestedBatches(t *testing.T) {
+	var batchHandler *BatchHandler
+
+	// Batches of every version reach Run, whatever their path.
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batchHandler.Run(w, r)
+	})
+	batchHandler = NewBatchHandler(api, nil)
+
+	body := `{"operations":[{"method":"POST","path":"/v2/batch","body":{"operations":[{"method":"GET","path":"/v1/boards"}]}}]}`
+
+	rec := httptest.NewRecorder()
+	batchHandler.Run(rec, httptest.NewRequest(http.MethodPost, "/v1/batch", strings.NewReader(body)))
+
+	var response struct {
+		Data BatchResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	results := response.Data.Results
+	if len(results) != 1 || results[0].Status != http.StatusBadRequest {
+		t.Fatalf("results = %+v, want one 400", results)
+	}
+	if !strings.Contains(string(results[0].Body), `"batch_nested"`) {
+		t.Errorf("result body = %s, want batch_nested", results[0].Body)
+	}
+}
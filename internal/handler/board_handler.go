@@ -3,79 +3,233 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
 	"github.com/mithileshgupta12/velaris/internal/db/policy"
 	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/helper"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/transfer"
+	"github.com/mithileshgupta12/velaris/internal/validation"
 )
 
+// TrelloImportMaxBytes is the body limit of Trello imports, which replaces
+// the one of the rest of the API: exports carry every card, action and
+// checklist of the board and routinely run past it.
+const TrelloImportMaxBytes = 32 << 20
+
 type BoardRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// TemplateId is only honoured when creating a board. It is either the id
+	// of a template board or the id of a built-in template.
+	TemplateId *string `json:"template_id"`
+}
+
+type DuplicateBoardRequest struct {
+	Name string `json:"name"`
 }
 
+type ImportBoardResponse struct {
+	Board *models.Board   `json:"board"`
+	Ids   *transfer.IdMap `json:"ids"`
+}
+
+var (
+	errTemplateNotFound     = apperror.New(apperror.KindInvalid, "template_not_found", "template not found")
+	errExportFormatNotFound = apperror.New(apperror.KindNotFound, "export_format_not_supported", "export format not supported")
+	errBoardCreateForbidden = apperror.New(apperror.KindForbidden, "board_create_forbidden", "guests cannot create boards")
+	errTrelloExportTooLarge = apperror.New(apperror.KindPayloadTooLarge, "trello_export_too_large", fmt.Sprintf("trello board export must not be larger than %d MB", TrelloImportMaxBytes>>20))
+)
+
 type BoardHandler struct {
-	boardRepository repository.BoardRepository
-	boardPolicy     policy.Policy
+	boardRepository   repository.BoardRepository
+	listRepository    repository.ListRepository
+	watcherRepository repository.WatcherRepository
+	boardPolicy       policy.Policy
 }
 
-func NewBoardHandler(boardRepository repository.BoardRepository, boardPolicy policy.Policy) *BoardHandler {
-	return &BoardHandler{boardRepository, boardPolicy}
+func NewBoardHandler(
+	boardRepository repository.BoardRepository,
+	listRepository repository.ListRepository,
+	watcherRepository repository.WatcherRepository,
+	boardPolicy policy.Policy,
+) *BoardHandler {
+	return &BoardHandler{boardRepository, listRepository, watcherRepository, boardPolicy}
+}
+
+// authorizeCreate checks that the user may create boards, writing the
+// response otherwise.
+func (bh *BoardHandler) authorizeCreate(w http.ResponseWriter, r *http.Request, ctxUser middleware.CtxUser) bool {
+	canCreate, err := bh.boardPolicy.CanCreate(ctxUser, 0)
+	if err != nil {
+		slog.Error("failed to check board create permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return false
+	}
+	if !canCreate {
+		helper.AppErrorJsonResponse(w, r, errBoardCreateForbidden)
+		return false
+	}
+
+	return true
+}
+
+// boardIncludes returns the relations the user may include with boards.
+// Guests can't list other users, so they get neither owners nor watchers.
+func boardIncludes(ctxUser middleware.CtxUser) []string {
+	if ctxUser.IsGuest {
+		return []string{"lists"}
+	}
+
+	return []string{"lists", "owner", "watchers"}
 }
 
 func (bh *BoardHandler) validateBoardData(name, description string) error {
-	name = strings.TrimSpace(name)
-	description = strings.TrimSpace(description)
+	v := validation.New()
 
-	if name == "" {
-		return errors.New("name is a required field")
+	v.String("name", strings.TrimSpace(name), validation.Required(), validation.MaxLength(255))
+	v.String("description", strings.TrimSpace(description), validation.MaxLength(10000))
+
+	return v.Err()
+}
+
+// templateLists resolves templateId to the lists a board created from the
+// template starts with.
+func (bh *BoardHandler) templateLists(ctxUser middleware.CtxUser, templateId string) ([]*repository.CreateBoardListArgs, error) {
+	if builtin := models.GetBuiltinTemplate(templateId); builtin != nil {
+		lists := make([]*repository.CreateBoardListArgs, 0, len(builtin.Lists))
+		for _, list := range builtin.Lists {
+			lists = append(lists, &repository.CreateBoardListArgs{
+				Name:     list.Name,
+				Position: list.Position,
+			})
+		}
+
+		return lists, nil
+	}
+
+	boardId, err := strconv.ParseInt(templateId, 10, 64)
+	if err != nil || boardId < 1 {
+		return nil, errTemplateNotFound
 	}
 
-	if len(name) > 255 {
-		return errors.New("name must not be more than 255 characters long")
+	canView, err := bh.boardPolicy.CanView(ctxUser, boardId)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, errTemplateNotFound
+	}
+
+	board, err := bh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+		Id: boardId,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrBoardNotFound) {
+			return nil, errTemplateNotFound
+		}
+
+		return nil, err
+	}
+	if !board.IsTemplate {
+		return nil, errTemplateNotFound
 	}
 
-	if len(description) > 10000 {
-		return errors.New("description must not be more than 10,000 characters long")
+	templateLists, _, err := bh.listRepository.GetAllListsByBoardId(&repository.GetAllListsByBoardIdArgs{
+		BoardId: boardId,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	lists := make([]*repository.CreateBoardListArgs, 0, len(templateLists))
+	for _, list := range templateLists {
+		lists = append(lists, &repository.CreateBoardListArgs{
+			Name:     list.Name,
+			Position: list.Position,
+		})
+	}
+
+	return lists, nil
 }
 
 func (bh *BoardHandler) Index(w http.ResponseWriter, r *http.Request) {
+	archived, err := helper.ParseBoolQueryParam(r, "archived")
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "archived must be a boolean")
+		return
+	}
+
+	watching, err := helper.ParseBoolQueryParam(r, "watching")
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "watching must be a boolean")
+		return
+	}
+
+	page, err := helper.ParsePageQuery(r, helper.PageOptions{
+		Sorts:       []string{"name", "created_at", "updated_at"},
+		DefaultSort: "created_at",
+		Filters:     []string{"name"},
+	})
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
 	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
 
-	boards, err := bh.boardRepository.GetAllBoardsByUserId(ctxUser.ID)
+	include, err := helper.ParseIncludes(r, boardIncludes(ctxUser)...)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	boards, next, err := bh.boardRepository.GetAllBoardsByUserId(&repository.GetAllBoardsByUserIdArgs{
+		UserId:   ctxUser.ID,
+		Archived: archived,
+		Watching: watching,
+		Page:     page,
+		Include:  include,
+	})
 	if err != nil {
 		slog.Error("failed to get boards", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	helper.JsonResponse(w, http.StatusOK, boards)
+	helper.PaginatedJsonResponse(w, r, http.StatusOK, boards, next)
 }
 
 func (bh *BoardHandler) Store(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	if !bh.authorizeCreate(w, r, ctxUser) {
+		return
+	}
+
 	var createBoardRequest BoardRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&createBoardRequest); err != nil {
 		slog.Error("failed to decode request", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid request")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	err := bh.validateBoardData(createBoardRequest.Name, createBoardRequest.Description)
 	if err != nil {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, err.Error())
+		helper.InvalidRequestJsonResponse(w, r, err)
 		return
 	}
 
-	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
-
 	createBoardArgs := &repository.CreateBoardArgs{
 		Name:   createBoardRequest.Name,
 		UserId: ctxUser.ID,
@@ -87,10 +241,20 @@ func (bh *BoardHandler) Store(w http.ResponseWriter, r *http.Request) {
 		createBoardArgs.Description = &createBoardRequest.Description
 	}
 
+	if createBoardRequest.TemplateId != nil {
+		lists, err := bh.templateLists(ctxUser, *createBoardRequest.TemplateId)
+		if err != nil {
+			helper.AppErrorJsonResponse(w, r, err)
+			return
+		}
+
+		createBoardArgs.Lists = lists
+	}
+
 	board, err := bh.boardRepository.CreateBoard(createBoardArgs)
 	if err != nil {
 		slog.Error("failed to create board", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -100,39 +264,58 @@ func (bh *BoardHandler) Store(w http.ResponseWriter, r *http.Request) {
 func (bh *BoardHandler) Show(w http.ResponseWriter, r *http.Request) {
 	id, err := helper.ParseIntURLParam(r, "id")
 	if err != nil || id < 1 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid board id")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
 		return
 	}
 
 	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
 
+	include, err := helper.ParseIncludes(r, boardIncludes(ctxUser)...)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
 	canView, err := bh.boardPolicy.CanView(ctxUser, int64(id))
 	if err != nil {
 		slog.Error("failed to check board view permission", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if !canView {
-		helper.ErrorJsonResponse(w, http.StatusNotFound, "board not found")
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
 		return
 	}
 
 	board, err := bh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
-		Id: int64(id),
+		Id:      int64(id),
+		Include: include,
 	})
 	if err != nil {
 		slog.Error("failed to get board by ID", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
+	// Included relations change without bumping the board's version, so
+	// those responses are left to the body-derived ETag of the middleware.
+	if len(include) == 0 {
+		helper.SetVersionETag(w, board.Version)
+	}
+
 	helper.JsonResponse(w, http.StatusOK, board)
 }
 
 func (bh *BoardHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := helper.ParseIntURLParam(r, "id")
 	if err != nil || id < 1 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid board id")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
 		return
 	}
 
@@ -141,11 +324,11 @@ func (bh *BoardHandler) Update(w http.ResponseWriter, r *http.Request) {
 	canUpdate, err := bh.boardPolicy.CanUpdate(ctxUser, int64(id))
 	if err != nil {
 		slog.Error("failed to check board update permission", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if !canUpdate {
-		helper.ErrorJsonResponse(w, http.StatusNotFound, "board not found")
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
 		return
 	}
 
@@ -153,19 +336,21 @@ func (bh *BoardHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&updateBoardRequest); err != nil {
 		slog.Error("failed to decode request", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid request")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	err = bh.validateBoardData(updateBoardRequest.Name, updateBoardRequest.Description)
 	if err != nil {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, err.Error())
+		helper.InvalidRequestJsonResponse(w, r, err)
 		return
 	}
 
 	updateBoardByIdArgs := &repository.UpdateBoardByIdArgs{
-		Id:   int64(id),
-		Name: updateBoardRequest.Name,
+		Id:      int64(id),
+		Name:    updateBoardRequest.Name,
+		Version: version,
+		ActorId: ctxUser.ID,
 	}
 
 	if updateBoardRequest.Description == "" {
@@ -176,18 +361,143 @@ func (bh *BoardHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	board, err := bh.boardRepository.UpdateBoardById(updateBoardByIdArgs)
 	if err != nil {
-		slog.Error("failed to update board", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.AppErrorJsonResponse(w, r, err)
 		return
 	}
 
+	helper.SetVersionETag(w, board.Version)
+	helper.JsonResponse(w, http.StatusOK, board)
+}
+
+// Patch applies a JSON Merge Patch to the board. Only the fields present in
+// the patch are written; a null description clears it.
+func (bh *BoardHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := bh.boardPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	patch, err := helper.DecodeMergePatch(r)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	if err := patch.CheckFields("name", "description"); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	board, err := bh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+		Id: id,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	updateBoardByIdArgs := &repository.UpdateBoardByIdArgs{
+		Id:          id,
+		Name:        board.Name,
+		Description: board.Description,
+		Version:     version,
+		ActorId:     ctxUser.ID,
+	}
+
+	if patch.Has("name") {
+		// A null name is left empty for validateBoardData to reject.
+		updateBoardByIdArgs.Name = ""
+		if !patch.IsNull("name") {
+			if err := patch.Decode("name", &updateBoardByIdArgs.Name); err != nil {
+				helper.InvalidRequestJsonResponse(w, r, err)
+				return
+			}
+		}
+
+		updateBoardByIdArgs.Columns = append(updateBoardByIdArgs.Columns, "name")
+	}
+
+	if patch.Has("description") {
+		var description string
+		if !patch.IsNull("description") {
+			if err := patch.Decode("description", &description); err != nil {
+				helper.InvalidRequestJsonResponse(w, r, err)
+				return
+			}
+		}
+
+		if description == "" {
+			updateBoardByIdArgs.Description = nil
+		} else {
+			updateBoardByIdArgs.Description = &description
+		}
+
+		updateBoardByIdArgs.Columns = append(updateBoardByIdArgs.Columns, "description")
+	}
+
+	var description string
+	if updateBoardByIdArgs.Description != nil {
+		description = *updateBoardByIdArgs.Description
+	}
+
+	err = bh.validateBoardData(updateBoardByIdArgs.Name, description)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	// An empty patch changes nothing and must not bump the version.
+	if len(updateBoardByIdArgs.Columns) == 0 {
+		if version != 0 && version != board.Version {
+			helper.AppErrorJsonResponse(w, r, repository.ErrBoardVersionMismatch)
+			return
+		}
+
+		helper.SetVersionETag(w, board.Version)
+		helper.JsonResponse(w, http.StatusOK, board)
+		return
+	}
+
+	board, err = bh.boardRepository.UpdateBoardById(updateBoardByIdArgs)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.SetVersionETag(w, board.Version)
 	helper.JsonResponse(w, http.StatusOK, board)
 }
 
 func (bh *BoardHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 	id, err := helper.ParseIntURLParam(r, "id")
 	if err != nil || id < 1 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid board id")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
 		return
 	}
 
@@ -195,22 +505,414 @@ func (bh *BoardHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 
 	canDelete, err := bh.boardPolicy.CanDelete(ctxUser, int64(id))
 	if err != nil {
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if !canDelete {
-		helper.ErrorJsonResponse(w, http.StatusNotFound, "board not found")
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
 		return
 	}
 
 	err = bh.boardRepository.DeleteBoardById(&repository.DeleteBoardByIdArgs{
-		Id: int64(id),
+		Id:      int64(id),
+		Version: version,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (bh *BoardHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := bh.boardPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	board, err := bh.boardRepository.ArchiveBoardById(&repository.ArchiveBoardByIdArgs{
+		Id:      id,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, board)
+}
+
+func (bh *BoardHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := bh.boardPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	board, err := bh.boardRepository.UnarchiveBoardById(&repository.UnarchiveBoardByIdArgs{
+		Id:      id,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, board)
+}
+
+func (bh *BoardHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canView, err := bh.boardPolicy.CanView(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	if !bh.authorizeCreate(w, r, ctxUser) {
+		return
+	}
+
+	var duplicateBoardRequest DuplicateBoardRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&duplicateBoardRequest); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	name := strings.TrimSpace(duplicateBoardRequest.Name)
+	if name == "" {
+		source, err := bh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+			Id: id,
+		})
+		if err != nil {
+			slog.Error("failed to get board by ID", "err", err)
+			helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		name = source.Name
+		if len(name+" (copy)") <= 255 {
+			name += " (copy)"
+		}
+	}
+
+	v := validation.New()
+	v.String("name", name, validation.MaxLength(255))
+
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	board, err := bh.boardRepository.DuplicateBoardById(&repository.DuplicateBoardByIdArgs{
+		Id:     id,
+		UserId: ctxUser.ID,
+		Name:   name,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, board)
+}
+
+func (bh *BoardHandler) MarkAsTemplate(w http.ResponseWriter, r *http.Request) {
+	bh.setTemplate(w, r, true)
+}
+
+func (bh *BoardHandler) UnmarkAsTemplate(w http.ResponseWriter, r *http.Request) {
+	bh.setTemplate(w, r, false)
+}
+
+func (bh *BoardHandler) setTemplate(w http.ResponseWriter, r *http.Request, isTemplate bool) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := bh.boardPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	board, err := bh.boardRepository.SetBoardTemplateById(&repository.SetBoardTemplateByIdArgs{
+		Id:         id,
+		IsTemplate: isTemplate,
+		ActorId:    ctxUser.ID,
 	})
 	if err != nil {
-		slog.Error("failed to delete board", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, board)
+}
+
+// Watch makes the user watch the board, notifying them of everything that
+// happens on it.
+func (bh *BoardHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	bh.setWatching(w, r, true)
+}
+
+func (bh *BoardHandler) Unwatch(w http.ResponseWriter, r *http.Request) {
+	bh.setWatching(w, r, false)
+}
+
+func (bh *BoardHandler) setWatching(w http.ResponseWriter, r *http.Request, watching bool) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canView, err := bh.boardPolicy.CanView(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	if watching {
+		err = bh.watcherRepository.WatchBoard(&repository.WatchBoardArgs{
+			UserId:  ctxUser.ID,
+			BoardId: id,
+		})
+	} else {
+		err = bh.watcherRepository.UnwatchBoard(&repository.UnwatchBoardArgs{
+			UserId:  ctxUser.ID,
+			BoardId: id,
+		})
+	}
+	if err != nil {
+		slog.Error("failed to set board watching", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (bh *BoardHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	format := chi.URLParam(r, "format")
+	if format == "" {
+		format = "json"
+	}
+
+	exporter, ok := transfer.GetExporter(format)
+	if !ok {
+		helper.AppErrorJsonResponse(w, r, errExportFormatNotFound)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canView, err := bh.boardPolicy.CanView(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check board view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	board, err := bh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+		Id: id,
+	})
+	if err != nil {
+		slog.Error("failed to get board by ID", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	board.Lists, _, err = bh.listRepository.GetAllListsByBoardId(&repository.GetAllListsByBoardIdArgs{
+		BoardId:      id,
+		WithArchived: true,
+	})
+	if err != nil {
+		slog.Error("failed to get lists for board", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="board-%d.%s"`, id, format))
+	w.WriteHeader(http.StatusOK)
+
+	if err := exporter.Export(w, board); err != nil {
+		slog.Error("failed to export board", "format", format, "err", err)
+	}
+}
+
+func (bh *BoardHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	if !bh.authorizeCreate(w, r, ctxUser) {
+		return
+	}
+
+	document, err := transfer.DecodeDocument(r.Body)
+	if errors.Is(err, transfer.ErrUnsupportedVersion) {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to decode board document", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if errs := document.Validate(); len(errs) > 0 {
+		helper.ErrorDetailsJsonResponse(w, r, http.StatusUnprocessableEntity, "invalid board document", errs)
+		return
+	}
+
+	board, err := bh.boardRepository.CreateBoard(document.CreateBoardArgs(ctxUser.ID))
+	if err != nil {
+		slog.Error("failed to import board", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, &ImportBoardResponse{
+		Board: board,
+		Ids:   document.IdMap(board),
+	})
+}
+
+func (bh *BoardHandler) ImportTrello(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	if !bh.authorizeCreate(w, r, ctxUser) {
+		return
+	}
+
+	dryRun, err := helper.ParseBoolQueryParam(r, "dry_run")
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "dry_run must be a boolean")
+		return
+	}
+
+	source := io.Reader(r.Body)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if isMaxBytesError(err) {
+			helper.AppErrorJsonResponse(w, r, errTrelloExportTooLarge)
+			return
+		}
+		if err != nil {
+			helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "file is a required field")
+			return
+		}
+		defer file.Close()
+
+		source = file
+	}
+
+	trelloBoard, err := transfer.DecodeTrelloBoard(source)
+	if isMaxBytesError(err) {
+		helper.AppErrorJsonResponse(w, r, errTrelloExportTooLarge)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to decode trello board", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid trello board export")
+		return
+	}
+
+	createBoardArgs, report, err := transfer.PlanTrelloImport(trelloBoard, ctxUser.ID)
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if dryRun {
+		report.DryRun = true
+		helper.JsonResponse(w, http.StatusOK, report)
+		return
+	}
+
+	board, err := bh.boardRepository.CreateBoard(createBoardArgs)
+	if err != nil {
+		slog.Error("failed to import trello board", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	report.SetCreated(board)
+
+	helper.JsonResponse(w, http.StatusCreated, report)
+}
+
+// isMaxBytesError reports whether err comes from reading past the body limit
+// of the request.
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
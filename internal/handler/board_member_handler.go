@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/invitation"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+)
+
+type BoardInvitationRequest struct {
+	Email string `json:"email"`
+	// Role is the role the invitation grants, editor or viewer.
+	Role string `json:"role"`
+	// Guest makes the account of an invitee who registers through the
+	// invitation a guest account.
+	Guest bool `json:"guest"`
+}
+
+type AcceptBoardInvitationRequest struct {
+	// Token is the token of the link emailed with the invitation.
+	Token string `json:"token"`
+}
+
+var errMemberListForbidden = apperror.New(apperror.KindForbidden, "member_list_forbidden", "guests cannot list board members")
+
+type BoardMemberHandler struct {
+	boardMemberRepository     repository.BoardMemberRepository
+	boardInvitationRepository repository.BoardInvitationRepository
+	boardPolicy               policy.BoardPolicy
+	appKey                    string
+}
+
+func NewBoardMemberHandler(
+	boardMemberRepository repository.BoardMemberRepository,
+	boardInvitationRepository repository.BoardInvitationRepository,
+	boardPolicy policy.BoardPolicy,
+	appKey string,
+) *BoardMemberHandler {
+	return &BoardMemberHandler{boardMemberRepository, boardInvitationRepository, boardPolicy, appKey}
+}
+
+// authorize parses the board id of the URL and checks that the user passes
+// can on it, writing the response otherwise.
+func (bmh *BoardMemberHandler) authorize(
+	w http.ResponseWriter,
+	r *http.Request,
+	can func(ctxUser middleware.CtxUser, id int64) (bool, error),
+) (int64, bool) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return 0, false
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	allowed, err := can(ctxUser, boardId)
+	if err != nil {
+		slog.Error("failed to check board permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return 0, false
+	}
+	if !allowed {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return 0, false
+	}
+
+	return boardId, true
+}
+
+// Members lists the owner and the members of the board with their roles, and
+// whether they are guests. Guests can't list them.
+func (bmh *BoardMemberHandler) Members(w http.ResponseWriter, r *http.Request) {
+	boardId, ok := bmh.authorize(w, r, bmh.boardPolicy.CanView)
+	if !ok {
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canViewMembers, err := bmh.boardPolicy.CanViewMembers(ctxUser, boardId)
+	if err != nil {
+		slog.Error("failed to check board members permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canViewMembers {
+		helper.AppErrorJsonResponse(w, r, errMemberListForbidden)
+		return
+	}
+
+	members, err := bmh.boardMemberRepository.GetAllBoardMembersByBoardId(boardId)
+	if err != nil {
+		slog.Error("failed to get board members", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, members)
+}
+
+// RemoveMember removes a member from the board. Members may remove
+// themselves to leave it.
+func (bmh *BoardMemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userId, err := helper.ParseIntURLParam(r, "userId")
+	if err != nil || userId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	can := bmh.boardPolicy.CanManageMembers
+	if userId == ctxUser.ID {
+		can = bmh.boardPolicy.CanView
+	}
+
+	boardId, ok := bmh.authorize(w, r, can)
+	if !ok {
+		return
+	}
+
+	err = bmh.boardMemberRepository.DeleteBoardMember(&repository.DeleteBoardMemberArgs{
+		BoardId: boardId,
+		UserId:  userId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Invitations lists the invitations to the board that can still be
+// accepted.
+func (bmh *BoardMemberHandler) Invitations(w http.ResponseWriter, r *http.Request) {
+	boardId, ok := bmh.authorize(w, r, bmh.boardPolicy.CanManageMembers)
+	if !ok {
+		return
+	}
+
+	invitations, err := bmh.boardInvitationRepository.GetAllBoardInvitationsByBoardId(boardId)
+	if err != nil {
+		slog.Error("failed to get board invitations", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, invitations)
+}
+
+// Invite invites an email to the board. The invitation is emailed by the
+// worker shortly after.
+func (bmh *BoardMemberHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	boardId, ok := bmh.authorize(w, r, bmh.boardPolicy.CanManageMembers)
+	if !ok {
+		return
+	}
+
+	var invitationRequest BoardInvitationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&invitationRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	invitationRequest.Email = strings.ToLower(strings.TrimSpace(invitationRequest.Email))
+
+	v := validation.New()
+
+	v.String("email", invitationRequest.Email, validation.Required(), validation.MaxLength(255), validation.Email())
+	v.String("role", invitationRequest.Role, validation.Required(), validation.OneOf(models.BoardInvitationRoles...))
+
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	boardInvitation, err := bmh.boardInvitationRepository.CreateBoardInvitation(&repository.CreateBoardInvitationArgs{
+		BoardId:   boardId,
+		Email:     invitationRequest.Email,
+		Role:      invitationRequest.Role,
+		Guest:     invitationRequest.Guest,
+		InvitedBy: ctxUser.ID,
+		ExpiresAt: time.Now().Add(invitation.Lifetime),
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, boardInvitation)
+}
+
+// Revoke deletes an invitation, so that its link stops working.
+func (bmh *BoardMemberHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid invitation id")
+		return
+	}
+
+	boardId, ok := bmh.authorize(w, r, bmh.boardPolicy.CanManageMembers)
+	if !ok {
+		return
+	}
+
+	err = bmh.boardInvitationRepository.DeleteBoardInvitation(&repository.DeleteBoardInvitationArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Accept makes the user a member of the board of the invitation whose token
+// they got by email. Users without an account accept while registering.
+func (bmh *BoardMemberHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var acceptRequest AcceptBoardInvitationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&acceptRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	v := validation.New()
+	v.String("token", acceptRequest.Token, validation.Required())
+
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	id, err := invitation.ParseToken(bmh.appKey, acceptRequest.Token)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	member, err := bmh.boardInvitationRepository.AcceptBoardInvitation(&repository.AcceptBoardInvitationArgs{
+		Id:     id,
+		UserId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, member)
+}
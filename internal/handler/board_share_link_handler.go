@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+)
+
+// SharePasswordHeader carries the password of the share links that ask for
+// one.
+const SharePasswordHeader = "X-Share-Password"
+
+const (
+	// maxSharePasswordFailures is the number of wrong passwords a share link
+	// takes per sharePasswordWindow before refusing to check more.
+	maxSharePasswordFailures = 10
+	sharePasswordWindow      = 15 * time.Minute
+)
+
+type BoardShareLinkRequest struct {
+	// ExpiresAt is when the link stops working. It works until revoked when
+	// omitted.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Password, when set, is asked of anyone opening the link.
+	Password string `json:"password"`
+}
+
+// PublicBoardResponse is a board as shown through a share link, without
+// anything about the people working on it.
+type PublicBoardResponse struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description"`
+	Lists       []*models.List `json:"lists"`
+	ArchivedAt  *time.Time     `json:"archived_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+var (
+	errSharePasswordRequired = apperror.New(apperror.KindUnauthenticated, "share_password_required", "share link requires a password")
+	errSharePasswordInvalid  = apperror.New(apperror.KindUnauthenticated, "share_password_invalid", "share link password is invalid")
+	errSharePasswordLocked   = apperror.New(apperror.KindTooManyRequests, "share_password_locked", "too many wrong passwords, try again later")
+)
+
+type BoardShareLinkHandler struct {
+	boardShareLinkRepository repository.BoardShareLinkRepository
+	boardRepository          repository.BoardRepository
+	boardPolicy              policy.BoardPolicy
+	attemptLimiter           cache.AttemptLimiter
+}
+
+func NewBoardShareLinkHandler(
+	boardShareLinkRepository repository.BoardShareLinkRepository,
+	boardRepository repository.BoardRepository,
+	boardPolicy policy.BoardPolicy,
+	attemptLimiter cache.AttemptLimiter,
+) *BoardShareLinkHandler {
+	return &BoardShareLinkHandler{boardShareLinkRepository, boardRepository, boardPolicy, attemptLimiter}
+}
+
+// authorize parses the board id of the URL and checks that the user may
+// share the board, which only its owner may.
+func (bslh *BoardShareLinkHandler) authorize(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return 0, false
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canShare, err := bslh.boardPolicy.CanManageMembers(ctxUser, boardId)
+	if err != nil {
+		slog.Error("failed to check board share permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return 0, false
+	}
+	if !canShare {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return 0, false
+	}
+
+	return boardId, true
+}
+
+// Index lists the share links of the board that did not expire. Their
+// tokens are only shown when they are created.
+func (bslh *BoardShareLinkHandler) Index(w http.ResponseWriter, r *http.Request) {
+	boardId, ok := bslh.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := bslh.boardShareLinkRepository.GetAllBoardShareLinksByBoardId(boardId)
+	if err != nil {
+		slog.Error("failed to get board share links", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, links)
+}
+
+func (bslh *BoardShareLinkHandler) Store(w http.ResponseWriter, r *http.Request) {
+	boardId, ok := bslh.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var shareLinkRequest BoardShareLinkRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&shareLinkRequest); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	v := validation.New()
+
+	v.String("password", shareLinkRequest.Password, validation.MaxLength(255))
+	if shareLinkRequest.ExpiresAt != nil {
+		v.Check("expires_at", shareLinkRequest.ExpiresAt.After(time.Now()), validation.CodeInvalid, "expires_at must be in the future")
+	}
+
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	createArgs := &repository.CreateBoardShareLinkArgs{
+		BoardId:   boardId,
+		ExpiresAt: shareLinkRequest.ExpiresAt,
+		CreatedBy: ctxUser.ID,
+	}
+
+	if shareLinkRequest.Password != "" {
+		passwordHash, err := helper.HashPassword(shareLinkRequest.Password)
+		if err != nil {
+			slog.Error("failed to hash password", "err", err)
+			helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		createArgs.PasswordHash = &passwordHash
+	}
+
+	link, err := bslh.boardShareLinkRepository.CreateBoardShareLink(createArgs)
+	if err != nil {
+		slog.Error("failed to create board share link", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, link)
+}
+
+// Destroy revokes a share link.
+func (bslh *BoardShareLinkHandler) Destroy(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid share link id")
+		return
+	}
+
+	boardId, ok := bslh.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	err = bslh.boardShareLinkRepository.DeleteBoardShareLink(&repository.DeleteBoardShareLinkArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Public shows the board of a share link to anyone holding its token, with
+// the password of the link in SharePasswordHeader if it has one. The board
+// policy checks the password; wrong ones are counted, and refused for a
+// while once there are too many.
+func (bslh *BoardShareLinkHandler) Public(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	link, err := bslh.boardShareLinkRepository.GetBoardShareLinkByToken(token)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := middleware.CtxUser{ShareToken: token}
+	attemptKey := fmt.Sprintf("share_password:%d", link.Id)
+
+	if link.PasswordHash != nil {
+		// Responses depend on a secret header, so no cache may keep them.
+		w.Header().Set("Cache-Control", "no-store")
+
+		ctxUser.SharePassword = r.Header.Get(SharePasswordHeader)
+		if ctxUser.SharePassword == "" {
+			helper.AppErrorJsonResponse(w, r, errSharePasswordRequired)
+			return
+		}
+
+		failures, err := bslh.attemptLimiter.Failures(r.Context(), attemptKey)
+		if err != nil {
+			slog.Error("failed to count share password failures", "err", err)
+			helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if failures >= maxSharePasswordFailures {
+			helper.AppErrorJsonResponse(w, r, errSharePasswordLocked)
+			return
+		}
+	}
+
+	canView, err := bslh.boardPolicy.CanView(ctxUser, link.BoardId)
+	if err != nil {
+		slog.Error("failed to check board view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView && link.PasswordHash != nil {
+		if err := bslh.attemptLimiter.Fail(r.Context(), attemptKey, sharePasswordWindow); err != nil {
+			slog.Error("failed to count share password failure", "err", err)
+		}
+
+		helper.AppErrorJsonResponse(w, r, errSharePasswordInvalid)
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardShareLinkNotFound)
+		return
+	}
+
+	board, err := bslh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+		Id:      link.BoardId,
+		Include: []string{"lists"},
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, &PublicBoardResponse{
+		Name:        board.Name,
+		Description: board.Description,
+		Lists:       board.Lists,
+		ArchivedAt:  board.ArchivedAt,
+		UpdatedAt:   board.UpdatedAt,
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/digest"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+)
+
+type DigestRequest struct {
+	// Frequency is immediate, hourly, daily or off.
+	Frequency string `json:"frequency"`
+}
+
+type DigestHandler struct {
+	digestRepository repository.DigestRepository
+	appKey           string
+	frontendUrl      string
+}
+
+func NewDigestHandler(digestRepository repository.DigestRepository, appKey, frontendUrl string) *DigestHandler {
+	return &DigestHandler{digestRepository, appKey, frontendUrl}
+}
+
+func (dh *DigestHandler) Show(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	setting, err := dh.digestRepository.GetDigestSettingByUserId(ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to get digest setting", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, setting)
+}
+
+func (dh *DigestHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	var digestRequest DigestRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&digestRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	v := validation.New()
+	v.String("frequency", digestRequest.Frequency, validation.Required(), validation.OneOf(models.DigestFrequencies...))
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	setting, err := dh.digestRepository.SetDigestFrequency(&repository.SetDigestFrequencyArgs{
+		UserId:    ctxUser.ID,
+		Frequency: digestRequest.Frequency,
+	})
+	if err != nil {
+		slog.Error("failed to set digest frequency", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, setting)
+}
+
+// ConfirmUnsubscribe shows the people following an unsubscribe link a form
+// posting to Unsubscribe. It changes nothing itself, since mail scanners and
+// link prefetchers GET every link of an email.
+func (dh *DigestHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	if _, err := helper.VerifyToken(dh.appKey, digest.UnsubscribePurpose, token); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := digest.RenderUnsubscribe(w, dh.frontendUrl, token); err != nil {
+		slog.Error("failed to render unsubscribe page", "err", err)
+	}
+}
+
+// Unsubscribe turns off the digests of the user named by the signed token
+// of an unsubscribe link, without a session. Mail clients POST to it for
+// one-click unsubscription (RFC 8058), as does the ConfirmUnsubscribe form.
+func (dh *DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	subject, err := helper.VerifyToken(dh.appKey, digest.UnsubscribePurpose, r.URL.Query().Get("token"))
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	userId, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, helper.ErrInvalidToken)
+		return
+	}
+
+	_, err = dh.digestRepository.SetDigestFrequency(&repository.SetDigestFrequencyArgs{
+		UserId:    userId,
+		Frequency: models.DigestOff,
+	})
+	if err != nil {
+		slog.Error("failed to unsubscribe from digests", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := digest.RenderUnsubscribed(w, dh.frontendUrl); err != nil {
+		slog.Error("failed to render unsubscribed page", "err", err)
+	}
+}
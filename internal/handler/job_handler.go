@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+)
+
+type JobHandler struct {
+	queue *jobs.Queue
+}
+
+func NewJobHandler(queue *jobs.Queue) *JobHandler {
+	return &JobHandler{queue}
+}
+
+// Status reports the depth of the job queue, the outcomes per job type and
+// the last jobs that failed.
+func (jh *JobHandler) Status(w http.ResponseWriter, r *http.Request) {
+	status, err := jh.queue.Status(r.Context())
+	if err != nil {
+		slog.Error("failed to get job queue status", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, status)
+}
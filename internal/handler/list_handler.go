@@ -1,33 +1,96 @@
 package handler
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strings"
 
+	"github.com/mithileshgupta12/velaris/internal/apperror"
 	"github.com/mithileshgupta12/velaris/internal/db/policy"
 	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/helper"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
 )
 
+type ListRequest struct {
+	Name     string `json:"name"`
+	Position *int   `json:"position"`
+}
+
 type ListHandler struct {
-	listRepository repository.ListRepository
-	boardPolicy    policy.Policy
-	listPolicy     policy.Policy
+	listRepository    repository.ListRepository
+	boardRepository   repository.BoardRepository
+	watcherRepository repository.WatcherRepository
+	boardPolicy       policy.Policy
+	listPolicy        policy.Policy
 }
 
 func NewListHandler(
 	listRepository repository.ListRepository,
+	boardRepository repository.BoardRepository,
+	watcherRepository repository.WatcherRepository,
 	boardPolicy policy.Policy,
 	listPolicy policy.Policy,
 ) *ListHandler {
-	return &ListHandler{listRepository, boardPolicy, listPolicy}
+	return &ListHandler{listRepository, boardRepository, watcherRepository, boardPolicy, listPolicy}
+}
+
+func (lh *ListHandler) validateListData(name string, position *int) error {
+	v := validation.New()
+
+	v.String("name", strings.TrimSpace(name), validation.Required(), validation.MaxLength(255))
+	v.Int("position", position, validation.Min(1))
+
+	return v.Err()
+}
+
+var errBoardArchived = apperror.New(apperror.KindConflict, "board_archived", "board is archived")
+
+// checkBoardWritable returns errBoardArchived when the board is archived.
+// Archived boards are read-only, so every list mutation checks this before
+// touching the list.
+func (lh *ListHandler) checkBoardWritable(boardId int64) error {
+	board, err := lh.boardRepository.GetBoardById(&repository.GetBoardByIdArgs{
+		Id: boardId,
+	})
+	if err != nil {
+		return err
+	}
+	if board.ArchivedAt != nil {
+		return errBoardArchived
+	}
+
+	return nil
 }
 
 func (lh *ListHandler) Index(w http.ResponseWriter, r *http.Request) {
 	boardId, err := helper.ParseIntURLParam(r, "boardId")
 	if err != nil || boardId < 1 {
-		helper.ErrorJsonResponse(w, http.StatusBadRequest, "invalid board id")
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	archived, err := helper.ParseBoolQueryParam(r, "archived")
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "archived must be a boolean")
+		return
+	}
+
+	page, err := helper.ParsePageQuery(r, helper.PageOptions{
+		Sorts:       []string{"position", "name", "created_at", "updated_at"},
+		DefaultSort: "position",
+		Filters:     []string{"name"},
+	})
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	include, err := helper.ParseIncludes(r, "board")
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
 		return
 	}
 
@@ -36,38 +99,529 @@ func (lh *ListHandler) Index(w http.ResponseWriter, r *http.Request) {
 	canView, err := lh.boardPolicy.CanView(ctxUser, boardId)
 	if err != nil {
 		slog.Error("failed to check board view permission", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if !canView {
-		helper.ErrorJsonResponse(w, http.StatusNotFound, "board not found")
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
 		return
 	}
 
-	lists, err := lh.listRepository.GetAllListsByBoardId(&repository.GetAllListsByBoardIdArgs{
-		BoardId: boardId,
+	lists, next, err := lh.listRepository.GetAllListsByBoardId(&repository.GetAllListsByBoardIdArgs{
+		BoardId:  boardId,
+		Archived: archived,
+		Page:     page,
+		Include:  include,
 	})
 	if err != nil {
 		slog.Error("failed to get lists for board", "err", err)
-		helper.ErrorJsonResponse(w, http.StatusInternalServerError, "internal server error")
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	helper.JsonResponse(w, http.StatusOK, lists)
+	helper.PaginatedJsonResponse(w, r, http.StatusOK, lists, next)
 }
 
 func (lh *ListHandler) Store(w http.ResponseWriter, r *http.Request) {
-	//
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := lh.boardPolicy.CanUpdate(ctxUser, boardId)
+	if err != nil {
+		slog.Error("failed to check board update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrBoardNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	var createListRequest ListRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&createListRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	err = lh.validateListData(createListRequest.Name, createListRequest.Position)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	list, err := lh.listRepository.CreateList(&repository.CreateListArgs{
+		Name:     strings.TrimSpace(createListRequest.Name),
+		BoardId:  boardId,
+		Position: createListRequest.Position,
+		ActorId:  ctxUser.ID,
+	})
+	if err != nil {
+		slog.Error("failed to create list", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, list)
 }
 
 func (lh *ListHandler) Show(w http.ResponseWriter, r *http.Request) {
-	//
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	include, err := helper.ParseIncludes(r, "board")
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canView, err := lh.listPolicy.CanView(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	list, err := lh.listRepository.GetListById(&repository.GetListByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+		Include: include,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	// Included relations change without bumping the list's version, so
+	// those responses are left to the body-derived ETag of the middleware.
+	if len(include) == 0 {
+		helper.SetVersionETag(w, list.Version)
+	}
+
+	helper.JsonResponse(w, http.StatusOK, list)
 }
 
 func (lh *ListHandler) Update(w http.ResponseWriter, r *http.Request) {
-	//
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := lh.listPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	var updateListRequest ListRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&updateListRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	err = lh.validateListData(updateListRequest.Name, updateListRequest.Position)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	list, err := lh.listRepository.UpdateListById(&repository.UpdateListByIdArgs{
+		Id:       id,
+		BoardId:  boardId,
+		Name:     strings.TrimSpace(updateListRequest.Name),
+		Position: updateListRequest.Position,
+		Version:  version,
+		ActorId:  ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.SetVersionETag(w, list.Version)
+	helper.JsonResponse(w, http.StatusOK, list)
+}
+
+// Patch applies a JSON Merge Patch to the list. Only the fields present in
+// the patch are written.
+func (lh *ListHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := lh.listPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	patch, err := helper.DecodeMergePatch(r)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	if err := patch.CheckFields("name", "position"); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	list, err := lh.listRepository.GetListById(&repository.GetListByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	updateListByIdArgs := &repository.UpdateListByIdArgs{
+		Id:       id,
+		BoardId:  boardId,
+		Name:     list.Name,
+		Position: &list.Position,
+		Version:  version,
+		ActorId:  ctxUser.ID,
+	}
+
+	if patch.Has("name") {
+		// A null name is left empty for validateListData to reject.
+		updateListByIdArgs.Name = ""
+		if !patch.IsNull("name") {
+			if err := patch.Decode("name", &updateListByIdArgs.Name); err != nil {
+				helper.InvalidRequestJsonResponse(w, r, err)
+				return
+			}
+		}
+
+		updateListByIdArgs.Name = strings.TrimSpace(updateListByIdArgs.Name)
+		updateListByIdArgs.Columns = append(updateListByIdArgs.Columns, "name")
+	}
+
+	if patch.Has("position") {
+		if patch.IsNull("position") {
+			v := validation.New()
+			v.Add("position", validation.CodeRequired, "position must not be null")
+
+			helper.InvalidRequestJsonResponse(w, r, v.Err())
+			return
+		}
+
+		var position int
+		if err := patch.Decode("position", &position); err != nil {
+			helper.InvalidRequestJsonResponse(w, r, err)
+			return
+		}
+
+		updateListByIdArgs.Position = &position
+		updateListByIdArgs.Columns = append(updateListByIdArgs.Columns, "position")
+	}
+
+	err = lh.validateListData(updateListByIdArgs.Name, updateListByIdArgs.Position)
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	// An empty patch changes nothing and must not bump the version.
+	if len(updateListByIdArgs.Columns) == 0 {
+		if version != 0 && version != list.Version {
+			helper.AppErrorJsonResponse(w, r, repository.ErrListVersionMismatch)
+			return
+		}
+
+		helper.SetVersionETag(w, list.Version)
+		helper.JsonResponse(w, http.StatusOK, list)
+		return
+	}
+
+	list, err = lh.listRepository.UpdateListById(updateListByIdArgs)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.SetVersionETag(w, list.Version)
+	helper.JsonResponse(w, http.StatusOK, list)
 }
 
 func (lh *ListHandler) Destroy(w http.ResponseWriter, r *http.Request) {
-	//
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	version, err := helper.ParseIfMatchVersion(r)
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canDelete, err := lh.listPolicy.CanDelete(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list delete permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canDelete {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	err = lh.listRepository.DeleteListById(&repository.DeleteListByIdArgs{
+		ListId:  id,
+		BoardId: boardId,
+		Version: version,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (lh *ListHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := lh.listPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	list, err := lh.listRepository.ArchiveListById(&repository.ArchiveListByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, list)
+}
+
+func (lh *ListHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := lh.listPolicy.CanUpdate(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list update permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canUpdate {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	if err := lh.checkBoardWritable(boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	list, err := lh.listRepository.UnarchiveListById(&repository.UnarchiveListByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+		ActorId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, list)
+}
+
+// Watch makes the user watch the list, notifying them of what happens to it
+// without watching the whole board.
+func (lh *ListHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	lh.setWatching(w, r, true)
+}
+
+func (lh *ListHandler) Unwatch(w http.ResponseWriter, r *http.Request) {
+	lh.setWatching(w, r, false)
+}
+
+func (lh *ListHandler) setWatching(w http.ResponseWriter, r *http.Request, watching bool) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid list id")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canView, err := lh.listPolicy.CanView(ctxUser, id)
+	if err != nil {
+		slog.Error("failed to check list view permission", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !canView {
+		helper.AppErrorJsonResponse(w, r, repository.ErrListNotFound)
+		return
+	}
+
+	// The list must be on the board of the URL.
+	if _, err := lh.listRepository.GetListById(&repository.GetListByIdArgs{Id: id, BoardId: boardId}); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	if watching {
+		err = lh.watcherRepository.WatchList(&repository.WatchListArgs{
+			UserId:  ctxUser.ID,
+			BoardId: boardId,
+			ListId:  id,
+		})
+	} else {
+		err = lh.watcherRepository.UnwatchList(&repository.UnwatchListArgs{
+			UserId: ctxUser.ID,
+			ListId: id,
+		})
+	}
+	if err != nil {
+		slog.Error("failed to set list watching", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/notification"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+)
+
+// streamHeartbeat is how often an idle notification stream sends a comment,
+// which keeps proxies from closing it.
+const streamHeartbeat = 30 * time.Second
+
+type NotificationsResponse struct {
+	// UnreadCount counts every unread notification of the user, not only
+	// the ones on this page.
+	UnreadCount   int64                  `json:"unread_count"`
+	Notifications []*models.Notification `json:"notifications"`
+}
+
+type NotificationPreferencesRequest struct {
+	// Events maps event names to whether they notify. Events left out keep
+	// their preference.
+	Events map[string]bool `json:"events"`
+}
+
+type NotificationHandler struct {
+	notificationRepository repository.NotificationRepository
+	notificationBroker     cache.NotificationBroker
+}
+
+func NewNotificationHandler(
+	notificationRepository repository.NotificationRepository,
+	notificationBroker cache.NotificationBroker,
+) *NotificationHandler {
+	return &NotificationHandler{notificationRepository, notificationBroker}
+}
+
+func (nh *NotificationHandler) Index(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	unread, err := helper.ParseBoolQueryParam(r, "unread")
+	if err != nil {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "unread must be a boolean")
+		return
+	}
+
+	page, err := helper.ParsePageQuery(r, helper.PageOptions{
+		Sorts:        []string{"created_at"},
+		DefaultSort:  "-created_at",
+		Filters:      []string{"event"},
+		DefaultLimit: helper.DefaultPageLimit,
+	})
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	notifications, next, err := nh.notificationRepository.GetAllNotificationsByUserId(&repository.GetAllNotificationsByUserIdArgs{
+		UserId: ctxUser.ID,
+		Unread: unread,
+		Page:   page,
+	})
+	if err != nil {
+		slog.Error("failed to get notifications", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	unreadCount, err := nh.notificationRepository.CountUnreadNotificationsByUserId(ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to count unread notifications", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.PaginatedJsonResponse(w, r, http.StatusOK, &NotificationsResponse{
+		UnreadCount:   unreadCount,
+		Notifications: notifications,
+	}, next)
+}
+
+func (nh *NotificationHandler) Read(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid notification id")
+		return
+	}
+
+	readNotification, err := nh.notificationRepository.MarkNotificationRead(&repository.MarkNotificationReadArgs{
+		Id:     id,
+		UserId: ctxUser.ID,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, readNotification)
+}
+
+func (nh *NotificationHandler) ReadAll(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	if _, err := nh.notificationRepository.MarkAllNotificationsRead(ctxUser.ID); err != nil {
+		slog.Error("failed to mark notifications read", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preferences lists every event that can notify with whether it notifies
+// the user.
+func (nh *NotificationHandler) Preferences(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	preferences, err := nh.preferences(ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to get notification preferences", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, preferences)
+}
+
+func (nh *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	var preferencesRequest NotificationPreferencesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&preferencesRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	v := validation.New()
+	for event := range preferencesRequest.Events {
+		v.String(fmt.Sprintf("events[%s]", event), event, validation.OneOf(notification.Events...))
+	}
+	if err := v.Err(); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	err := nh.notificationRepository.SetNotificationPreferences(&repository.SetNotificationPreferencesArgs{
+		UserId: ctxUser.ID,
+		Events: preferencesRequest.Events,
+	})
+	if err != nil {
+		slog.Error("failed to set notification preferences", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	preferences, err := nh.preferences(ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to get notification preferences", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, preferences)
+}
+
+// preferences returns the preference of the user for every notifying event,
+// enabled unless turned off.
+func (nh *NotificationHandler) preferences(userId int64) ([]*models.NotificationPreference, error) {
+	saved, err := nh.notificationRepository.GetNotificationPreferencesByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]*models.NotificationPreference, 0, len(notification.Events))
+	for _, event := range notification.Events {
+		preference := &models.NotificationPreference{UserId: userId, Event: event, Enabled: true}
+
+		index := slices.IndexFunc(saved, func(s *models.NotificationPreference) bool {
+			return s.Event == event
+		})
+		if index >= 0 {
+			preference.Enabled = saved[index].Enabled
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+// Stream pushes the user's new notifications as server-sent events named
+// "notification" until the client disconnects. It is authenticated by the
+// session cookie like any other request, which EventSource sends.
+func (nh *NotificationHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "streaming is not supported")
+		return
+	}
+
+	subscription, err := nh.notificationBroker.Subscribe(r.Context(), ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to subscribe to notifications", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case data, ok := <-subscription.Messages():
+			if !ok {
+				return
+			}
+
+			fmt.Fprintf(w, "event: notification\ndata: %s\n\n", data)
+		}
+
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchTerms     = 10
+)
+
+type SearchResponse struct {
+	Hits    []*models.SearchHit `json:"hits"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	HasMore bool                `json:"has_more"`
+}
+
+type SearchHandler struct {
+	searchRepository repository.SearchRepository
+	boardPolicy      policy.BoardPolicy
+}
+
+func NewSearchHandler(searchRepository repository.SearchRepository, boardPolicy policy.BoardPolicy) *SearchHandler {
+	return &SearchHandler{searchRepository, boardPolicy}
+}
+
+// prefixQuery turns free text into a to_tsquery expression matching every
+// word as a prefix, e.g. "road map" becomes "road:* & map:*".
+func prefixQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func (sh *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := prefixQuery(r.URL.Query().Get("q"))
+	if query == "" {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "q is a required field")
+		return
+	}
+
+	page, err := helper.ParseIntQueryParam(r, "page", 1)
+	if err != nil || page < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "page must be a positive integer")
+		return
+	}
+
+	limit, err := helper.ParseIntQueryParam(r, "limit", defaultSearchLimit)
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "limit must be between 1 and 100")
+		return
+	}
+
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	scope, scopeArgs := sh.boardPolicy.ViewScope(ctxUser, "b.id")
+
+	hits, err := sh.searchRepository.Search(&repository.SearchArgs{
+		Query:     query,
+		Scope:     scope,
+		ScopeArgs: scopeArgs,
+		Limit:     limit + 1,
+		Offset:    (page - 1) * limit,
+	})
+	if err != nil {
+		slog.Error("failed to search", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	hasMore := len(hits) > limit
+	if hasMore {
+		hits = hits[:limit]
+	}
+
+	helper.JsonResponse(w, http.StatusOK, &SearchResponse{
+		Hits:    hits,
+		Page:    page,
+		Limit:   limit,
+		HasMore: hasMore,
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+type TemplateHandler struct {
+	boardRepository repository.BoardRepository
+	listRepository  repository.ListRepository
+}
+
+func NewTemplateHandler(boardRepository repository.BoardRepository, listRepository repository.ListRepository) *TemplateHandler {
+	return &TemplateHandler{boardRepository, listRepository}
+}
+
+func (th *TemplateHandler) Index(w http.ResponseWriter, r *http.Request) {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	boards, err := th.boardRepository.GetAllTemplateBoardsByUserId(ctxUser.ID)
+	if err != nil {
+		slog.Error("failed to get template boards", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	boardIds := make([]int64, 0, len(boards))
+	for _, board := range boards {
+		boardIds = append(boardIds, board.Id)
+	}
+
+	lists, err := th.listRepository.GetAllListsByBoardIds(&repository.GetAllListsByBoardIdsArgs{
+		BoardIds: boardIds,
+	})
+	if err != nil {
+		slog.Error("failed to get lists for template boards", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	listsByBoardId := make(map[int64][]*models.TemplateList, len(boards))
+	for _, list := range lists {
+		listsByBoardId[list.BoardId] = append(listsByBoardId[list.BoardId], &models.TemplateList{
+			Name:     list.Name,
+			Position: list.Position,
+		})
+	}
+
+	templates := make([]*models.Template, 0, len(boards)+len(models.BuiltinTemplates))
+	for _, board := range boards {
+		templateLists := listsByBoardId[board.Id]
+		if templateLists == nil {
+			templateLists = []*models.TemplateList{}
+		}
+
+		templates = append(templates, &models.Template{
+			Id:          strconv.FormatInt(board.Id, 10),
+			Name:        board.Name,
+			Description: board.Description,
+			Lists:       templateLists,
+		})
+	}
+	templates = append(templates, models.BuiltinTemplates...)
+
+	helper.JsonResponse(w, http.StatusOK, templates)
+}
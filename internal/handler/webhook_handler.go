@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/validation"
+	"github.com/mithileshgupta12/velaris/internal/webhook"
+)
+
+type WebhookRequest struct {
+	Url string `json:"url"`
+	// Secret signs the deliveries; see webhook.Sign.
+	Secret string `json:"secret"`
+	// Events filters the events delivered. Empty subscribes to all.
+	Events []string `json:"events"`
+}
+
+type WebhookHandler struct {
+	webhookRepository repository.WebhookRepository
+	boardPolicy       policy.Policy
+}
+
+func NewWebhookHandler(
+	webhookRepository repository.WebhookRepository,
+	boardPolicy policy.Policy,
+) *WebhookHandler {
+	return &WebhookHandler{webhookRepository, boardPolicy}
+}
+
+func (wh *WebhookHandler) validateWebhookData(ctx context.Context, webhookRequest *WebhookRequest) error {
+	v := validation.New()
+
+	v.String("url", webhookRequest.Url, validation.Required(), validation.MaxLength(2048))
+	if webhookRequest.Url != "" {
+		target, err := url.Parse(webhookRequest.Url)
+		isHttp := err == nil && (target.Scheme == "http" || target.Scheme == "https") && target.Host != ""
+		v.Check("url", isHttp, validation.CodeInvalid, "url must be an http or https URL")
+
+		// Deliveries must not reach into the network the API runs in.
+		if isHttp {
+			v.Check(
+				"url",
+				webhook.CheckURL(ctx, webhookRequest.Url) == nil,
+				validation.CodeInvalid,
+				"url must resolve to public addresses only",
+			)
+		}
+	}
+
+	v.String("secret", webhookRequest.Secret, validation.Required(), validation.MinLength(16), validation.MaxLength(255))
+
+	for i, event := range webhookRequest.Events {
+		v.String(fmt.Sprintf("events[%d]", i), event, validation.Required(), validation.OneOf(webhook.Events...))
+	}
+
+	return v.Err()
+}
+
+// authorize returns repository.ErrBoardNotFound unless the user may manage
+// the webhooks of the board, which takes the right to update it.
+func (wh *WebhookHandler) authorize(r *http.Request, boardId int64) error {
+	ctxUser := r.Context().Value(middleware.CtxUserKey).(middleware.CtxUser)
+
+	canUpdate, err := wh.boardPolicy.CanUpdate(ctxUser, boardId)
+	if err != nil {
+		return err
+	}
+	if !canUpdate {
+		return repository.ErrBoardNotFound
+	}
+
+	return nil
+}
+
+func (wh *WebhookHandler) Index(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	if err := wh.authorize(r, boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	webhooks, err := wh.webhookRepository.GetAllWebhooksByBoardId(&repository.GetAllWebhooksByBoardIdArgs{
+		BoardId: boardId,
+	})
+	if err != nil {
+		slog.Error("failed to get webhooks for board", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, webhooks)
+}
+
+func (wh *WebhookHandler) Store(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	var webhookRequest WebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&webhookRequest); err != nil {
+		slog.Error("failed to decode request", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	webhookRequest.Url = strings.TrimSpace(webhookRequest.Url)
+
+	if err := wh.validateWebhookData(r.Context(), &webhookRequest); err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	if err := wh.authorize(r, boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	events := slices.Clone(webhookRequest.Events)
+	slices.Sort(events)
+
+	createdWebhook, err := wh.webhookRepository.CreateWebhook(&repository.CreateWebhookArgs{
+		BoardId: boardId,
+		Url:     webhookRequest.Url,
+		Secret:  webhookRequest.Secret,
+		Events:  slices.Compact(events),
+	})
+	if err != nil {
+		slog.Error("failed to create webhook", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusCreated, createdWebhook)
+}
+
+func (wh *WebhookHandler) Show(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := wh.authorize(r, boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	foundWebhook, err := wh.webhookRepository.GetWebhookById(&repository.GetWebhookByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	helper.JsonResponse(w, http.StatusOK, foundWebhook)
+}
+
+func (wh *WebhookHandler) Destroy(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := wh.authorize(r, boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	err = wh.webhookRepository.DeleteWebhookById(&repository.DeleteWebhookByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries lists the deliveries of a webhook, newest first, with the
+// response code of each attempt.
+func (wh *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	boardId, err := helper.ParseIntURLParam(r, "boardId")
+	if err != nil || boardId < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid board id")
+		return
+	}
+
+	id, err := helper.ParseIntURLParam(r, "id")
+	if err != nil || id < 1 {
+		helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	page, err := helper.ParsePageQuery(r, helper.PageOptions{
+		Sorts:        []string{"created_at"},
+		DefaultSort:  "-created_at",
+		Filters:      []string{"event", "status"},
+		DefaultLimit: helper.DefaultPageLimit,
+	})
+	if err != nil {
+		helper.InvalidRequestJsonResponse(w, r, err)
+		return
+	}
+
+	if err := wh.authorize(r, boardId); err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	_, err = wh.webhookRepository.GetWebhookById(&repository.GetWebhookByIdArgs{
+		Id:      id,
+		BoardId: boardId,
+	})
+	if err != nil {
+		helper.AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	deliveries, next, err := wh.webhookRepository.GetAllWebhookDeliveriesByWebhookId(&repository.GetAllWebhookDeliveriesByWebhookIdArgs{
+		WebhookId: id,
+		Page:      page,
+	})
+	if err != nil {
+		slog.Error("failed to get webhook deliveries", "err", err)
+		helper.ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	helper.PaginatedJsonResponse(w, r, http.StatusOK, deliveries, next)
+}
@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+)
+
+var ErrInvalidIfMatch = apperror.New(apperror.KindPreconditionFailed, "invalid_if_match", "If-Match must be a single strong ETag")
+
+// VersionETag returns the strong ETag of a resource at version.
+func VersionETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// SetVersionETag sets the ETag header of w to the ETag of version.
+func SetVersionETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", VersionETag(version))
+}
+
+// ParseIfMatchVersion returns the version named by the If-Match header of r.
+// A missing header or "*" yields zero, meaning any version is acceptable.
+func ParseIfMatchVersion(r *http.Request) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+
+	if !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) || len(ifMatch) < 2 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// ETagMatches reports whether etag is listed in the If-None-Match header
+// value. Comparison is weak, as RFC 9110 requires for If-None-Match.
+func ETagMatches(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
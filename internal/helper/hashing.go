@@ -2,7 +2,9 @@ package helper
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -106,3 +108,12 @@ func parseParam(param, prefix string) (int, error) {
 
 	return val, nil
 }
+
+// HashToken returns the SHA-256 of a random token in hex. Tokens are stored
+// hashed so that they can be looked up but not read back; they carry enough
+// entropy not to need a salt or a slow hash like passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
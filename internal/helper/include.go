@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ParseIncludes reads the comma separated include query parameter, e.g.
+// ?include=lists,owner, and rejects relations that are not in allowed.
+// Duplicates are dropped.
+func ParseIncludes(r *http.Request, allowed ...string) ([]string, error) {
+	param := r.URL.Query().Get("include")
+	if param == "" {
+		return nil, nil
+	}
+
+	includes := []string{}
+	for include := range strings.SplitSeq(param, ",") {
+		include = strings.TrimSpace(include)
+		if include == "" || slices.Contains(includes, include) {
+			continue
+		}
+
+		if !slices.Contains(allowed, include) {
+			return nil, fmt.Errorf("include must be one of %s", strings.Join(allowed, ", "))
+		}
+
+		includes = append(includes, include)
+	}
+
+	return includes, nil
+}
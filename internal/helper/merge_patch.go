@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+)
+
+var (
+	ErrUnsupportedPatchType = apperror.New(apperror.KindUnsupportedMediaType, "unsupported_patch_type", "content type must be application/merge-patch+json")
+	ErrInvalidMergePatch    = apperror.New(apperror.KindInvalid, "invalid_merge_patch", "merge patch must be a JSON object")
+)
+
+// MergePatch is a decoded JSON Merge Patch (RFC 7396) document. Members left
+// out of the document are absent from the map, members set to null are
+// present with the raw value null.
+type MergePatch map[string]json.RawMessage
+
+// DecodeMergePatch reads a merge patch from the body of r. Both
+// application/merge-patch+json and plain application/json are accepted.
+func DecodeMergePatch(r *http.Request) (MergePatch, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			return nil, ErrUnsupportedPatchType
+		}
+	}
+
+	var patch MergePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		return nil, ErrInvalidMergePatch
+	}
+
+	return patch, nil
+}
+
+// Has reports whether the patch mentions field, including setting it to null.
+func (mp MergePatch) Has(field string) bool {
+	_, ok := mp[field]
+
+	return ok
+}
+
+// IsNull reports whether the patch sets field to null.
+func (mp MergePatch) IsNull(field string) bool {
+	value, ok := mp[field]
+
+	return ok && bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
+
+// Decode unmarshals the value the patch sets field to into v.
+func (mp MergePatch) Decode(field string, v any) error {
+	if err := json.Unmarshal(mp[field], v); err != nil {
+		return fmt.Errorf("%s has an invalid value", field)
+	}
+
+	return nil
+}
+
+// CheckFields returns an error naming the first field of the patch that is not
+// in allowed.
+func (mp MergePatch) CheckFields(allowed ...string) error {
+	fields := make([]string, 0, len(mp))
+	for field := range mp {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	for _, field := range fields {
+		if !slices.Contains(allowed, field) {
+			return fmt.Errorf("unknown field %s", field)
+		}
+	}
+
+	return nil
+}
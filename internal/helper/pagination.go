@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/pagination"
+)
+
+const (
+	// DefaultPageLimit is the DefaultLimit of endpoints paginated from the
+	// start.
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidPageLimit = apperror.New(apperror.KindInvalid, "invalid_page_limit", fmt.Sprintf("limit must be between 1 and %d", MaxPageLimit))
+
+// PageOptions lists what a collection endpoint can be sorted and filtered by.
+type PageOptions struct {
+	Sorts []string
+	// DefaultSort is used without a sort parameter. A leading - sorts in
+	// descending order.
+	DefaultSort string
+	Filters     []string
+	// DefaultLimit is the page size without a limit parameter. Endpoints
+	// that returned every item before they were paginated leave it at 0,
+	// returning everything to clients that do not ask for pages.
+	DefaultLimit int
+}
+
+// ParsePageQuery reads the collection query parameters of r. Sort fields and
+// filters not listed in options are rejected.
+func ParsePageQuery(r *http.Request, options PageOptions) (*pagination.Query, error) {
+	query := r.URL.Query()
+
+	limit, err := ParseIntQueryParam(r, "limit", options.DefaultLimit)
+	if err != nil || limit > MaxPageLimit || (limit < 1 && query.Get("limit") != "") {
+		return nil, ErrInvalidPageLimit
+	}
+
+	pageQuery := &pagination.Query{
+		Limit: limit,
+		Sort:  strings.TrimPrefix(options.DefaultSort, "-"),
+		Desc:  strings.HasPrefix(options.DefaultSort, "-"),
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		pageQuery.Desc = strings.HasPrefix(sort, "-")
+		pageQuery.Sort = strings.TrimPrefix(sort, "-")
+
+		if !slices.Contains(options.Sorts, pageQuery.Sort) {
+			return nil, fmt.Errorf("sort must be one of %s", strings.Join(options.Sorts, ", "))
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		pageQuery.Cursor, err = pagination.DecodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if pageQuery.Cursor.Sort != pageQuery.SortParam() {
+			return nil, pagination.ErrInvalidCursor
+		}
+	}
+
+	for key, values := range query {
+		field, op := key, pagination.FilterEquals
+		if strings.HasSuffix(key, "~") {
+			field, op = strings.TrimSuffix(key, "~"), pagination.FilterContains
+		}
+
+		if !slices.Contains(options.Filters, field) {
+			continue
+		}
+
+		for _, value := range values {
+			pageQuery.Filters = append(pageQuery.Filters, pagination.Filter{
+				Field: field,
+				Op:    op,
+				Value: value,
+			})
+		}
+	}
+
+	return pageQuery, nil
+}
+
+// PaginatedJsonResponse writes data like JsonResponse and, when there is a
+// next page, adds next_cursor to the envelope and a Link header pointing at
+// the next page.
+func PaginatedJsonResponse(w http.ResponseWriter, r *http.Request, statusCode int, data any, next *pagination.Cursor) {
+	successResponse := &SuccessResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	if next != nil {
+		nextCursor := next.Encode()
+		successResponse.NextCursor = &nextCursor
+
+		nextURL := *r.URL
+		query := nextURL.Query()
+		query.Set("cursor", nextCursor)
+		nextURL.RawQuery = query.Encode()
+
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.RequestURI()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(successResponse); err != nil {
+		log.Printf("failed to encode json response: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePageQueryLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		defaultLimit int
+		want         int
+		wantErr      bool
+	}{
+		{"unpaginated by default", "", 0, 0, false},
+		{"default limit", "", DefaultPageLimit, DefaultPageLimit, false},
+		{"asked limit", "?limit=10", 0, 10, false},
+		{"zero limit", "?limit=0", 0, 0, true},
+		{"limit above max", "?limit=101", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/boards"+tt.query, nil)
+
+			page, err := ParsePageQuery(r, PageOptions{
+				Sorts:        []string{"created_at"},
+				DefaultSort:  "created_at",
+				DefaultLimit: tt.defaultLimit,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPageLimit) {
+					t.Errorf("err = %v, want %v", err, ErrInvalidPageLimit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePageQuery: %v", err)
+			}
+			if page.Limit != tt.want {
+				t.Errorf("limit = %d, want %d", page.Limit, tt.want)
+			}
+		})
+	}
+}
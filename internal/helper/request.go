@@ -17,3 +17,25 @@ func ParseIntURLParam(r *http.Request, urlParam string) (int64, error) {
 
 	return int64(id), err
 }
+
+// ParseBoolQueryParam reports whether the query parameter is set to a true
+// value. A missing parameter is treated as false.
+func ParseBoolQueryParam(r *http.Request, queryParam string) (bool, error) {
+	param := r.URL.Query().Get(queryParam)
+	if param == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(param)
+}
+
+// ParseIntQueryParam parses an integer query parameter. A missing parameter
+// yields defaultValue.
+func ParseIntQueryParam(r *http.Request, queryParam string, defaultValue int) (int, error) {
+	param := r.URL.Query().Get(queryParam)
+	if param == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(param)
+}
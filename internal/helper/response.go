@@ -2,8 +2,15 @@ package helper
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
+	"strings"
+
+	chiMiddlewares "github.com/go-chi/chi/v5/middleware"
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/validation"
 )
 
 type ErrorResponse struct {
@@ -12,12 +19,35 @@ type ErrorResponse struct {
 }
 
 type Error struct {
-	Message string `json:"message"`
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	RequestId string `json:"request_id,omitempty"`
+	Details   any    `json:"details,omitempty"`
+	// Fields and Codes list the messages and machine-readable codes of a
+	// failed validation by request field.
+	Fields map[string][]string `json:"fields,omitempty"`
+	Codes  map[string][]string `json:"codes,omitempty"`
+}
+
+// ProblemResponse is the RFC 7807 form of an error, served to clients that
+// accept application/problem+json.
+type ProblemResponse struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail"`
+	Instance  string              `json:"instance"`
+	Code      string              `json:"code"`
+	RequestId string              `json:"request_id,omitempty"`
+	Details   any                 `json:"details,omitempty"`
+	Fields    map[string][]string `json:"fields,omitempty"`
+	Codes     map[string][]string `json:"codes,omitempty"`
 }
 
 type SuccessResponse struct {
-	Success bool `json:"success"`
-	Data    any  `json:"data"`
+	Success    bool    `json:"success"`
+	Data       any     `json:"data"`
+	NextCursor *string `json:"next_cursor,omitempty"`
 }
 
 func JsonResponse(w http.ResponseWriter, statusCode int, data any) {
@@ -34,18 +64,129 @@ func JsonResponse(w http.ResponseWriter, statusCode int, data any) {
 	}
 }
 
-func ErrorJsonResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// ErrorJsonResponse writes an error with the default code of statusCode.
+func ErrorJsonResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	writeError(w, r, statusCode, Error{
+		Message: message,
+		Code:    apperror.StatusCode(statusCode),
+	})
+}
+
+// ErrorDetailsJsonResponse writes an error response carrying details, such as
+// the per-item errors of a rejected import, next to the message.
+func ErrorDetailsJsonResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, details any) {
+	writeError(w, r, statusCode, Error{
+		Message: message,
+		Code:    apperror.StatusCode(statusCode),
+		Details: details,
+	})
+}
 
-	errorResponse := &ErrorResponse{
+// AppErrorJsonResponse writes err with the status and code of the application
+// error it carries. Any other error is logged and hidden behind a 500.
+func AppErrorJsonResponse(w http.ResponseWriter, r *http.Request, err error) {
+	appErr, ok := apperror.From(err)
+	if !ok || appErr.Kind == apperror.KindInternal {
+		slog.Error(
+			"request failed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"request_id", chiMiddlewares.GetReqID(r.Context()),
+			"err", err,
+		)
+		ErrorJsonResponse(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeError(w, r, appErr.Kind.Status(), Error{
+		Message: appErr.Message,
+		Code:    appErr.Code,
+	})
+}
+
+// ValidationErrorJsonResponse writes every violation of errs, grouped by
+// field, with a 400 status. The message is the first violation so clients
+// that only read the message keep working.
+func ValidationErrorJsonResponse(w http.ResponseWriter, r *http.Request, errs *validation.Errors) {
+	responseError := Error{
+		Message: errs.Error(),
+		Code:    "validation_failed",
+		Fields:  map[string][]string{},
+		Codes:   map[string][]string{},
+	}
+
+	for _, field := range errs.Fields() {
+		for _, violation := range errs.Violations(field) {
+			responseError.Fields[field] = append(responseError.Fields[field], violation.Message)
+			responseError.Codes[field] = append(responseError.Codes[field], string(violation.Code))
+		}
+	}
+
+	writeError(w, r, http.StatusBadRequest, responseError)
+}
+
+// InvalidRequestJsonResponse writes err as the response to a malformed
+// request: validation errors with their fields, application errors with their
+// own status and code, and anything else as a plain 400.
+func InvalidRequestJsonResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var errs *validation.Errors
+	if errors.As(err, &errs) {
+		ValidationErrorJsonResponse(w, r, errs)
+		return
+	}
+
+	if _, ok := apperror.From(err); ok {
+		AppErrorJsonResponse(w, r, err)
+		return
+	}
+
+	ErrorJsonResponse(w, r, http.StatusBadRequest, err.Error())
+}
+
+// writeError writes responseError in the envelope every other response uses,
+// or as a problem document when the client asked for application/problem+json.
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, responseError Error) {
+	responseError.RequestId = chiMiddlewares.GetReqID(r.Context())
+
+	var body any = &ErrorResponse{
 		Success: false,
-		Error: Error{
-			Message: message,
-		},
+		Error:   responseError,
 	}
 
-	if err := json.NewEncoder(w).Encode(errorResponse); err != nil {
+	contentType := "application/json"
+	if acceptsProblem(r) {
+		contentType = "application/problem+json"
+		body = &ProblemResponse{
+			Type:      "/problems/" + responseError.Code,
+			Title:     http.StatusText(statusCode),
+			Status:    statusCode,
+			Detail:    responseError.Message,
+			Instance:  r.URL.RequestURI(),
+			Code:      responseError.Code,
+			RequestId: responseError.RequestId,
+			Details:   responseError.Details,
+			Fields:    responseError.Fields,
+			Codes:     responseError.Codes,
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
 		log.Printf("failed to encode json response: %v", err)
 	}
 }
+
+// acceptsProblem reports whether the Accept header of r lists
+// application/problem+json.
+func acceptsProblem(r *http.Request) bool {
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.TrimSpace(mediaType) == "application/problem+json" {
+			return true
+		}
+	}
+
+	return false
+}
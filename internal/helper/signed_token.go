@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+)
+
+var ErrInvalidToken = apperror.New(apperror.KindInvalid, "invalid_token", "token is invalid or expired")
+
+type signedTokenClaims struct {
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// SignToken returns a URL-safe token carrying subject, such as a user id,
+// that only holders of key can produce. purpose is signed along so that a
+// token issued for one use is rejected by another. A zero expiresAt never
+// expires.
+func SignToken(key, purpose, subject string, expiresAt time.Time) string {
+	claims := signedTokenClaims{Subject: subject}
+	if !expiresAt.IsZero() {
+		claims.ExpiresAt = expiresAt.Unix()
+	}
+
+	data, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	return payload + "." + tokenSignature(key, purpose, payload)
+}
+
+// VerifyToken returns the subject of a token SignToken issued with key and
+// purpose, or ErrInvalidToken if it was not, was tampered with or expired.
+func VerifyToken(key, purpose, token string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(tokenSignature(key, purpose, payload))) {
+		return "", ErrInvalidToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	var claims signedTokenClaims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return "", ErrInvalidToken
+	}
+
+	return claims.Subject, nil
+}
+
+func tokenSignature(key, purpose, payload string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(purpose + "." + payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,152 @@
+// Package invitation emails board invitations with a signed link accepting
+// them, and reads those links back.
+package invitation
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	htmltemplate "html/template"
+	"log/slog"
+	"net/url"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+	"github.com/mithileshgupta12/velaris/internal/mail"
+)
+
+const (
+	// TokenPurpose is the purpose of the signed tokens in invitation links,
+	// whose subject is the invitation id.
+	TokenPurpose = "board_invitation"
+
+	// Lifetime is how long an invitation can be accepted.
+	Lifetime = 7 * 24 * time.Hour
+
+	// batchSize caps the invitations emailed by one run of the send job.
+	batchSize = 100
+)
+
+var (
+	//go:embed templates
+	templates embed.FS
+
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templates, "templates/*.txt"))
+)
+
+// Token returns the token of the link accepting the invitation. It expires
+// with the invitation.
+func Token(appKey string, invitation *models.BoardInvitation) string {
+	return helper.SignToken(appKey, TokenPurpose, strconv.FormatInt(invitation.Id, 10), invitation.ExpiresAt)
+}
+
+// ParseToken returns the id of the invitation token was issued for, or
+// helper.ErrInvalidToken.
+func ParseToken(appKey, token string) (int64, error) {
+	subject, err := helper.VerifyToken(appKey, TokenPurpose, token)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return 0, helper.ErrInvalidToken
+	}
+
+	return id, nil
+}
+
+type Sender struct {
+	boardInvitationRepository repository.BoardInvitationRepository
+	mailer                    mail.Mailer
+	appKey                    string
+	frontendUrl               string
+}
+
+func NewSender(
+	boardInvitationRepository repository.BoardInvitationRepository,
+	mailer mail.Mailer,
+	appKey string,
+	frontendUrl string,
+) *Sender {
+	return &Sender{boardInvitationRepository, mailer, appKey, frontendUrl}
+}
+
+// Register adds the job emailing new invitations to queue, scheduled every
+// minute. Invitations are saved by the API without touching the queue, so
+// saving one inside a transaction emails it only once it commits.
+func (s *Sender) Register(queue *jobs.Queue) {
+	jobs.Register(queue, "invitations.send", jobs.Options{}, func(ctx context.Context, _ struct{}) error {
+		return s.SendPending(ctx)
+	}).Schedule("* * * * *", struct{}{})
+}
+
+type invitationData struct {
+	Subject   string
+	Inviter   string
+	BoardName string
+	Role      string
+	AcceptUrl string
+	ExpiresAt time.Time
+}
+
+// SendPending emails the invitations not emailed yet.
+func (s *Sender) SendPending(ctx context.Context) error {
+	invitations, err := s.boardInvitationRepository.GetUnsentBoardInvitations(batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, invitation := range invitations {
+		if err := s.send(ctx, invitation); err != nil {
+			return err
+		}
+
+		err := s.boardInvitationRepository.MarkBoardInvitationSent(&repository.MarkBoardInvitationSentArgs{
+			Id:        invitation.Id,
+			UpdatedAt: invitation.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		slog.Info("invitation sent", "invitation_id", invitation.Id, "board_id", invitation.BoardId)
+	}
+
+	return nil
+}
+
+func (s *Sender) send(ctx context.Context, invitation *models.UnsentBoardInvitation) error {
+	data := &invitationData{
+		Inviter:   "Someone",
+		BoardName: invitation.BoardName,
+		Role:      invitation.Role,
+		AcceptUrl: s.frontendUrl + "/invitations/accept?token=" + url.QueryEscape(Token(s.appKey, &invitation.BoardInvitation)),
+		ExpiresAt: invitation.ExpiresAt.UTC(),
+	}
+	if invitation.InviterName != nil {
+		data.Inviter = *invitation.InviterName
+	}
+	data.Subject = data.Inviter + " invited you to " + data.BoardName
+
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, "invitation.html", data); err != nil {
+		return err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, "invitation.txt", data); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mail.Message{
+		To:      invitation.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
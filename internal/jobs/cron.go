@@ -0,0 +1,215 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	schedulerInterval = 10 * time.Second
+	// leaderTTL is how long the scheduling leader may go silent before
+	// another process takes over.
+	leaderTTL = 30 * time.Second
+)
+
+type schedule struct {
+	// key identifies the schedule across processes.
+	key     string
+	spec    *cronSpec
+	enqueue func(ctx context.Context) error
+}
+
+// Schedule enqueues a job with args at the times spec matches, in UTC. spec
+// is a cron expression of five fields (minute, hour, day of month, month,
+// day of week) made of *, numbers, ranges, lists and /steps, or one of
+// @hourly, @daily and @weekly. A run is skipped while the previous one is
+// still queued or running. Schedule panics if spec is invalid.
+func (j *Job[T]) Schedule(spec string, args T) {
+	parsed, err := parseCronSpec(spec)
+	if err != nil {
+		panic(fmt.Sprintf("jobs: invalid schedule for %s: %v", j.name, err))
+	}
+
+	j.queue.schedules = append(j.queue.schedules, &schedule{
+		key:  j.name + " " + spec,
+		spec: parsed,
+		enqueue: func(ctx context.Context) error {
+			_, err := j.Enqueue(ctx, args, &EnqueueOptions{UniqueKey: "schedule"})
+			return err
+		},
+	})
+}
+
+// RunScheduler enqueues scheduled jobs until ctx is done. Every worker runs
+// it, but only the one holding the leader lock, identified by owner,
+// enqueues; the others stand by to take over if it goes away.
+func (q *Queue) RunScheduler(ctx context.Context, owner string) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	// checked is the last minute whose schedules were enqueued, zero while
+	// another process leads.
+	var checked time.Time
+
+	for {
+		leading, err := q.jobQueue.Lead(ctx, owner, leaderTTL)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to acquire scheduler lock", "err", err)
+		}
+
+		if !leading {
+			checked = time.Time{}
+		} else {
+			now := time.Now().UTC().Truncate(time.Minute)
+			if checked.IsZero() {
+				// The previous leader may have stopped before enqueueing
+				// the current minute; doing it twice is harmless.
+				slog.Info("leading job scheduler", "owner", owner)
+				checked = now.Add(-time.Minute)
+			}
+
+			for minute := checked.Add(time.Minute); !minute.After(now); minute = minute.Add(time.Minute) {
+				q.enqueueScheduled(ctx, minute)
+			}
+			checked = now
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) enqueueScheduled(ctx context.Context, minute time.Time) {
+	for _, s := range q.schedules {
+		if !s.spec.matches(minute) {
+			continue
+		}
+
+		// Leaders changing over could both get here for the same minute.
+		first, err := q.jobQueue.Once(ctx, fmt.Sprintf("%s %d", s.key, minute.Unix()), time.Hour)
+		if err != nil {
+			slog.Error("failed to enqueue scheduled job", "schedule", s.key, "err", err)
+			continue
+		}
+		if !first {
+			continue
+		}
+
+		if err := s.enqueue(ctx); err != nil {
+			slog.Error("failed to enqueue scheduled job", "schedule", s.key, "err", err)
+		}
+	}
+}
+
+// cronSpec holds the values each field matches as bits.
+type cronSpec struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// When both day fields are restricted a day matching either matches, as
+	// in cron.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+var cronMacros = map[string]string{
+	"@hourly": "0 * * * *",
+	"@daily":  "0 0 * * *",
+	"@weekly": "0 0 * * 0",
+}
+
+func parseCronSpec(spec string) (*cronSpec, error) {
+	if expanded, ok := cronMacros[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q must have 5 fields", spec)
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	values := [5]uint64{}
+
+	for i, field := range fields {
+		bits, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = bits
+	}
+
+	return &cronSpec{
+		minute:        values[0],
+		hour:          values[1],
+		dayOfMonth:    values[2],
+		month:         values[3],
+		dayOfWeek:     values[4],
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, low, high int) (uint64, error) {
+	var bits uint64
+
+	for part := range strings.SplitSeq(field, ",") {
+		valueRange, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepText)
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = parsed
+		}
+
+		start, end := low, high
+		if valueRange != "*" {
+			startText, endText, isRange := strings.Cut(valueRange, "-")
+
+			var err error
+			if start, err = strconv.Atoi(startText); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endText); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", part)
+				}
+			} else if hasStep {
+				end = high
+			}
+		}
+
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, low, high)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func (cs *cronSpec) matches(t time.Time) bool {
+	if cs.minute&(1<<t.Minute()) == 0 || cs.hour&(1<<t.Hour()) == 0 || cs.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	dayOfMonth := cs.dayOfMonth&(1<<t.Day()) != 0
+	dayOfWeek := cs.dayOfWeek&(1<<int(t.Weekday())) != 0
+
+	if cs.anyDayOfMonth || cs.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
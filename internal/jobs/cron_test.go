@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSpecMatches(t *testing.T) {
+	// 2026-10-19 is a Monday.
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"* * * * *", at(10, 19, 13, 37), true},
+		{"@hourly", at(10, 19, 13, 0), true},
+		{"@hourly", at(10, 19, 13, 1), false},
+		{"@daily", at(10, 19, 0, 0), true},
+		{"@daily", at(10, 19, 1, 0), false},
+		{"@weekly", at(10, 18, 0, 0), true},
+		{"@weekly", at(10, 19, 0, 0), false},
+
+		// Steps, over the whole field and from a start.
+		{"*/15 * * * *", at(10, 19, 13, 45), true},
+		{"*/15 * * * *", at(10, 19, 13, 50), false},
+		{"5/20 * * * *", at(10, 19, 13, 45), true},
+		{"5/20 * * * *", at(10, 19, 13, 40), false},
+
+		// Ranges, lists and stepped ranges.
+		{"0 9-17 * * *", at(10, 19, 17, 0), true},
+		{"0 9-17 * * *", at(10, 19, 18, 0), false},
+		{"0 8,12,18 * * *", at(10, 19, 12, 0), true},
+		{"0 8,12,18 * * *", at(10, 19, 13, 0), false},
+		{"0 0-12/6 * * *", at(10, 19, 6, 0), true},
+		{"0 0-12/6 * * *", at(10, 19, 18, 0), false},
+		{"0 0 * 1-6 *", at(10, 19, 0, 0), false},
+
+		// Only one day field restricted: it alone decides.
+		{"0 0 19 * *", at(10, 19, 0, 0), true},
+		{"0 0 20 * *", at(10, 19, 0, 0), false},
+		{"0 0 * * 1", at(10, 19, 0, 0), true},
+		{"0 0 * * 2", at(10, 19, 0, 0), false},
+
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 1 * 1", at(10, 19, 0, 0), true},
+		{"0 0 19 * 5", at(10, 19, 0, 0), true},
+		{"0 0 1 * 5", at(10, 19, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		spec, err := parseCronSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("parseCronSpec(%q): %v", tt.spec, err)
+		}
+
+		if got := spec.matches(tt.at); got != tt.want {
+			t.Errorf("%q matches %v = %v, want %v", tt.spec, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronSpecRejectsInvalidSpecs(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@yearly",
+	}
+
+	for _, spec := range specs {
+		if _, err := parseCronSpec(spec); err == nil {
+			t.Errorf("parseCronSpec(%q) succeeded, want an error", spec)
+		}
+	}
+}
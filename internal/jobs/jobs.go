@@ -0,0 +1,130 @@
+// Package jobs runs work outside the request path. Job types are registered
+// on a Queue with the handler that runs them; any process can enqueue them,
+// and `velaris worker` runs them.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+)
+
+const (
+	// VisibilityTimeout is how long a reserved job is hidden from other
+	// workers. A job not finished by then, for instance because its worker
+	// died, runs again.
+	VisibilityTimeout = 5 * time.Minute
+
+	defaultMaxAttempts = 5
+	defaultTimeout     = time.Minute
+)
+
+// Options configure a job type.
+type Options struct {
+	// MaxAttempts is how many times a failing job runs before it is failed
+	// for good. Defaults to 5.
+	MaxAttempts int
+	// Timeout bounds each run. It defaults to a minute and must be shorter
+	// than VisibilityTimeout.
+	Timeout time.Duration
+}
+
+// EnqueueOptions configure a single job.
+type EnqueueOptions struct {
+	// Delay postpones the job.
+	Delay time.Duration
+	// UniqueKey, when set, keeps a job of the same type and key from being
+	// enqueued while this one is queued or running.
+	UniqueKey string
+}
+
+type handler struct {
+	options Options
+	run     func(ctx context.Context, args json.RawMessage) error
+}
+
+type Queue struct {
+	jobQueue  cache.JobQueue
+	handlers  map[string]*handler
+	schedules []*schedule
+}
+
+func NewQueue(jobQueue cache.JobQueue) *Queue {
+	return &Queue{
+		jobQueue: jobQueue,
+		handlers: map[string]*handler{},
+	}
+}
+
+// Job enqueues jobs of a registered type with arguments of type T.
+type Job[T any] struct {
+	queue *Queue
+	name  string
+}
+
+// Register adds the job type name, run by run with the arguments it was
+// enqueued with decoded from JSON. It panics if name is taken or the options
+// are invalid, as these are programming errors.
+func Register[T any](q *Queue, name string, options Options, run func(ctx context.Context, args T) error) *Job[T] {
+	if _, ok := q.handlers[name]; ok {
+		panic(fmt.Sprintf("jobs: %s registered twice", name))
+	}
+
+	if options.MaxAttempts == 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+	if options.Timeout == 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.Timeout >= VisibilityTimeout {
+		panic(fmt.Sprintf("jobs: timeout of %s must be shorter than %s", name, VisibilityTimeout))
+	}
+
+	q.handlers[name] = &handler{
+		options: options,
+		run: func(ctx context.Context, data json.RawMessage) error {
+			var args T
+			if err := json.Unmarshal(data, &args); err != nil {
+				return fmt.Errorf("failed to decode arguments: %w", err)
+			}
+
+			return run(ctx, args)
+		},
+	}
+
+	return &Job[T]{q, name}
+}
+
+// Enqueue queues a job with args. It returns false, without error, when
+// another job holds options.UniqueKey.
+func (j *Job[T]) Enqueue(ctx context.Context, args T, options *EnqueueOptions) (bool, error) {
+	if options == nil {
+		options = &EnqueueOptions{}
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	job := &cache.QueuedJob{
+		Id:          rand.Text(),
+		Type:        j.name,
+		Args:        data,
+		UniqueKey:   options.UniqueKey,
+		MaxAttempts: j.queue.handlers[j.name].options.MaxAttempts,
+		EnqueuedAt:  now,
+	}
+
+	return j.queue.jobQueue.Enqueue(ctx, job, now.Add(options.Delay))
+}
+
+// Status reports the depth of the queue and the jobs that failed.
+func (q *Queue) Status(ctx context.Context) (*cache.JobQueueStatus, error) {
+	return q.jobQueue.Status(ctx, 20)
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/backoff"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+)
+
+const (
+	// pollInterval is the pause after the queue was found empty.
+	pollInterval = time.Second
+
+	baseRetryDelay = 15 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+// Work runs jobs with concurrency workers until ctx is done, then waits for
+// the running jobs to finish.
+func (q *Queue) Work(ctx context.Context, concurrency int) {
+	var wg sync.WaitGroup
+
+	for range concurrency {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				worked, err := q.WorkOne(ctx)
+				if err != nil {
+					slog.Error("failed to work job", "err", err)
+				}
+				if worked && err == nil {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(pollInterval):
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+// WorkOne runs the next due job, if any, and reports whether there was one.
+func (q *Queue) WorkOne(ctx context.Context) (bool, error) {
+	job, err := q.jobQueue.Reserve(ctx, VisibilityTimeout)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// The job's outcome is recorded even when ctx is cancelled meanwhile.
+	recordCtx := context.WithoutCancel(ctx)
+
+	runErr := q.run(ctx, job)
+	if runErr == nil {
+		return true, q.jobQueue.Complete(recordCtx, job)
+	}
+
+	job.LastError = runErr.Error()
+
+	if job.Attempts >= job.MaxAttempts {
+		slog.Error("job failed", "type", job.Type, "id", job.Id, "attempts", job.Attempts, "err", runErr)
+
+		failedAt := time.Now()
+		job.FailedAt = &failedAt
+
+		return true, q.jobQueue.Fail(recordCtx, job)
+	}
+
+	slog.Warn("job will be retried", "type", job.Type, "id", job.Id, "attempts", job.Attempts, "err", runErr)
+
+	return true, q.jobQueue.Retry(recordCtx, job, time.Now().Add(RetryDelay(job.Attempts)))
+}
+
+func (q *Queue) run(ctx context.Context, job *cache.QueuedJob) (err error) {
+	if job.LastError != "" && job.Type == "" {
+		// Reserve could not decode the job, which no retry will fix.
+		job.MaxAttempts = 0
+		return errors.New(job.LastError)
+	}
+
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		job.MaxAttempts = 0
+		return fmt.Errorf("unknown job type %q", job.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, handler.options.Timeout)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return handler.run(ctx, job.Args)
+}
+
+// RetryDelay returns how long to wait after the given failed job attempt: 15s
+// doubling up to an hour, see backoff.Delay.
+func RetryDelay(attempt int) time.Duration {
+	return backoff.Delay(attempt, baseRetryDelay, maxRetryDelay)
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelayBounds(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		minDelay time.Duration
+	}{
+		{1, 15 * time.Second},
+		{2, 30 * time.Second},
+		{3, time.Minute},
+		{8, 32 * time.Minute},
+		{9, time.Hour},
+		{20, time.Hour},
+		{1000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		// Jitter adds at most a tenth of the delay.
+		maxDelay := tt.minDelay + tt.minDelay/10
+
+		for range 100 {
+			if delay := RetryDelay(tt.attempt); delay < tt.minDelay || delay > maxDelay {
+				t.Fatalf("RetryDelay(%d) = %v, want between %v and %v", tt.attempt, delay, tt.minDelay, maxDelay)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileMailer struct {
+	from *mail.Address
+	dir  string
+}
+
+// NewFileMailer returns a mailer writing each message to an .eml file in dir
+// instead of sending it, for local development.
+func NewFileMailer(from *mail.Address, dir string) Mailer {
+	return &fileMailer{from, dir}
+}
+
+func (fm *fileMailer) Send(ctx context.Context, message *Message) error {
+	data, err := encode(fm.from, message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(fm.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), rand.Text()[:8])
+	path := filepath.Join(fm.dir, name)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+
+	slog.Info("mail written", "to", message.To, "subject", message.Subject, "path", path)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package mail sends email through a pluggable Mailer: SMTP in production,
+// or files in a directory for local development.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/config"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	// Text and HTML are alternative renderings of the body. Either may be
+	// empty.
+	Text string
+	HTML string
+	// Headers are added to the standard ones, e.g. List-Unsubscribe.
+	Headers map[string]string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// NewMailer returns the mailer selected by mailFlags.Driver.
+func NewMailer(mailFlags *config.MailFlags) (Mailer, error) {
+	from, err := mail.ParseAddress(mailFlags.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", mailFlags.From, err)
+	}
+
+	switch mailFlags.Driver {
+	case "smtp":
+		return NewSMTPMailer(from, mailFlags.SMTPHost, mailFlags.SMTPPort, mailFlags.SMTPUsername, mailFlags.SMTPPassword), nil
+	case "file":
+		return NewFileMailer(from, mailFlags.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", mailFlags.Driver)
+	}
+}
+
+// encode renders message as an RFC 5322 message from from, with the text and
+// HTML bodies as quoted-printable multipart/alternative parts.
+func encode(from *mail.Address, message *Message) ([]byte, error) {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", message.To, err)
+	}
+
+	var buf bytes.Buffer
+
+	_, domain, _ := strings.Cut(from.Address, "@")
+
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		fmt.Sprintf("Message-ID: <%s@%s>", rand.Text(), domain),
+		"MIME-Version: 1.0",
+	}
+	for _, name := range slices.Sorted(maps.Keys(message.Headers)) {
+		headers = append(headers, textproto.CanonicalMIMEHeaderKey(name)+": "+message.Headers[name])
+	}
+
+	body := multipart.NewWriter(&buf)
+	headers = append(headers, "Content-Type: multipart/alternative; boundary="+body.Boundary())
+
+	header := []byte(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return append(header, buf.Bytes()...), nil
+}
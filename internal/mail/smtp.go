@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// sendTimeout bounds sending a message when ctx allows longer.
+const sendTimeout = 30 * time.Second
+
+type smtpMailer struct {
+	from     *mail.Address
+	addr     string
+	host     string
+	username string
+	password string
+}
+
+// NewSMTPMailer returns a mailer sending through the SMTP server at host and
+// port, upgrading to TLS when the server offers it. It authenticates when
+// username is set.
+func NewSMTPMailer(from *mail.Address, host string, port int, username, password string) Mailer {
+	return &smtpMailer{
+		from:     from,
+		addr:     fmt.Sprintf("%s:%d", host, port),
+		host:     host,
+		username: username,
+		password: password,
+	}
+}
+
+func (sm *smtpMailer) Send(ctx context.Context, message *Message) error {
+	data, err := encode(sm.from, message)
+	if err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", sm.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// net/smtp takes no context, so the deadline of ctx is set on the
+	// connection and cancelling ctx expires it, failing any exchange a stalled
+	// server holds up.
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	client, err := smtp.NewClient(conn, sm.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sm.host}); err != nil {
+			return err
+		}
+	}
+
+	if sm.username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", sm.username, sm.password, sm.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sm.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	body, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := body.Write(data); err != nil {
+		return err
+	}
+	if err := body.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -18,8 +18,14 @@ type CtxUser struct {
 	ID        int64     `json:"id"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
+	IsGuest   bool      `json:"is_guest"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// ShareToken is set instead of ID on requests made anonymously through
+	// a board share link. It lets policies grant what the link grants, given
+	// SharePassword when the link has a password.
+	ShareToken    string `json:"-"`
+	SharePassword string `json:"-"`
 }
 
 const AuthCookieName = "auth_session"
@@ -28,7 +34,7 @@ func (m *middlewares) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sessionCookie, err := r.Cookie(AuthCookieName)
 		if err != nil {
-			helper.ErrorJsonResponse(w, http.StatusUnauthorized, "unauthenticated")
+			helper.ErrorJsonResponse(w, r, http.StatusUnauthorized, "unauthenticated")
 			return
 		}
 
@@ -37,7 +43,7 @@ func (m *middlewares) AuthMiddleware(next http.Handler) http.Handler {
 		sessionData, err := m.sessionStore.Get(r.Context(), sessionCookie.Value)
 		if err != nil {
 			helper.SetCookie(w, AuthCookieName, "", -1, isSecure)
-			helper.ErrorJsonResponse(w, http.StatusUnauthorized, "unauthenticated")
+			helper.ErrorJsonResponse(w, r, http.StatusUnauthorized, "unauthenticated")
 			return
 		}
 
@@ -48,7 +54,7 @@ func (m *middlewares) AuthMiddleware(next http.Handler) http.Handler {
 				slog.Error("failed to delete entry from session store", "err", err)
 			}
 			slog.Error("failed to convert userId to int", "err", err)
-			helper.ErrorJsonResponse(w, http.StatusUnauthorized, "unauthenticated")
+			helper.ErrorJsonResponse(w, r, http.StatusUnauthorized, "unauthenticated")
 			return
 		}
 
@@ -58,7 +64,7 @@ func (m *middlewares) AuthMiddleware(next http.Handler) http.Handler {
 			if err := m.sessionStore.Del(r.Context(), sessionCookie.Value); err != nil {
 				slog.Error("failed to delete entry from session store", "err", err)
 			}
-			helper.ErrorJsonResponse(w, http.StatusUnauthorized, "unauthenticated")
+			helper.ErrorJsonResponse(w, r, http.StatusUnauthorized, "unauthenticated")
 			return
 		}
 
@@ -66,6 +72,7 @@ func (m *middlewares) AuthMiddleware(next http.Handler) http.Handler {
 			ID:        user.Id,
 			Name:      user.Name,
 			Email:     user.Email,
+			IsGuest:   user.IsGuest,
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 		}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecation describes when a route stopped being recommended and when it
+// goes away.
+type Deprecation struct {
+	// Since is sent as the Deprecation header (RFC 9745).
+	Since time.Time
+	// Sunset is sent as the Sunset header (RFC 8594). Zero omits it.
+	Sunset time.Time
+	// Successor, when set, is prefixed to the request path to link the route
+	// that replaces this one, e.g. "/v1".
+	Successor string
+}
+
+// Deprecated marks the routes it wraps as deprecated.
+func Deprecated(deprecation Deprecation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+
+			header.Set("Deprecation", fmt.Sprintf("@%d", deprecation.Since.Unix()))
+
+			if !deprecation.Sunset.IsZero() {
+				header.Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if deprecation.Successor != "" {
+				header.Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, deprecation.Successor, r.URL.RequestURI()))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
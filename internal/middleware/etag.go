@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/helper"
+)
+
+// ETag answers conditional GET requests. Successful JSON responses without an
+// ETag of their own get a weak one derived from the body, and a request whose
+// If-None-Match lists the response's ETag gets 304 Not Modified instead of the
+// body. Downloads and streams are passed through untouched.
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(bw, r)
+
+		if bw.passthrough {
+			return
+		}
+
+		header := w.Header()
+		etag := header.Get("ETag")
+
+		if bw.statusCode == http.StatusOK && etag == "" {
+			sum := sha256.Sum256(bw.body.Bytes())
+			etag = `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+
+		if bw.statusCode == http.StatusOK && helper.ETagMatches(r.Header.Get("If-None-Match"), etag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(bw.statusCode)
+		w.Write(bw.body.Bytes())
+	})
+}
+
+// bufferedResponseWriter holds back JSON responses so ETag can inspect them
+// before anything is sent. Other responses are written through as they come.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	passthrough bool
+	body        bytes.Buffer
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if bw.wroteHeader {
+		return
+	}
+	bw.wroteHeader = true
+	bw.statusCode = statusCode
+
+	header := bw.Header()
+	if !strings.HasPrefix(header.Get("Content-Type"), "application/json") || header.Get("Content-Disposition") != "" {
+		bw.passthrough = true
+		bw.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (bw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+
+	if bw.passthrough {
+		return bw.ResponseWriter.Write(b)
+	}
+
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedResponseWriter) Flush() {
+	if !bw.wroteHeader {
+		bw.WriteHeader(http.StatusOK)
+	}
+
+	if !bw.passthrough {
+		return
+	}
+
+	if flusher, ok := bw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
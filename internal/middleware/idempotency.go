@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	// idempotencyClaimTTL bounds how long a crashed request blocks its key.
+	idempotencyClaimTTL = time.Minute
+	// idempotencyResponseTTL is how long responses are replayed to retries.
+	idempotencyResponseTTL = 24 * time.Hour
+)
+
+var (
+	errIdempotencyKeyTooLong  = apperror.New(apperror.KindInvalid, "idempotency_key_too_long", fmt.Sprintf("Idempotency-Key must not be more than %d characters long", maxIdempotencyKeyLen))
+	errIdempotencyKeyInFlight = apperror.New(apperror.KindConflict, "idempotency_key_in_flight", "a request with this Idempotency-Key is still being processed")
+	errIdempotencyKeyReused   = apperror.New(apperror.KindUnprocessable, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+)
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key safe
+// to retry. The first response for a user and key is saved and replayed to
+// later requests with the same key, marked with Idempotent-Replayed. It must
+// run after AuthMiddleware; anonymous requests are passed through.
+func (m *middlewares) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+		ctxUser, ok := r.Context().Value(CtxUserKey).(CtxUser)
+		if r.Method != http.MethodPost || idempotencyKey == "" || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(idempotencyKey) > maxIdempotencyKeyLen {
+			helper.AppErrorJsonResponse(w, r, errIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			helper.ErrorJsonResponse(w, r, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		if err != nil {
+			helper.ErrorJsonResponse(w, r, http.StatusBadRequest, "invalid request")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		key := fmt.Sprintf("%d:%s", ctxUser.ID, idempotencyKey)
+
+		record, claimed, err := m.idempotencyStore.Begin(r.Context(), key, requestHash, idempotencyClaimTTL)
+		if err != nil {
+			helper.AppErrorJsonResponse(w, r, fmt.Errorf("failed to claim idempotency key: %w", err))
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.RequestHash != requestHash:
+				helper.AppErrorJsonResponse(w, r, errIdempotencyKeyReused)
+			case record.Response == nil:
+				helper.AppErrorJsonResponse(w, r, errIdempotencyKeyInFlight)
+			default:
+				replayResponse(w, record.Response)
+			}
+			return
+		}
+
+		rw := &recordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+
+			// The handler panicked or failed; free the key so a retry runs
+			// the request again instead of waiting for the claim to expire.
+			if err := m.idempotencyStore.Release(r.Context(), key); err != nil {
+				slog.Error("failed to release idempotency key", "err", err)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+
+		if rw.statusCode >= http.StatusInternalServerError {
+			return
+		}
+
+		record.Response = &cache.IdempotentResponse{
+			StatusCode: rw.statusCode,
+			Header:     rw.header,
+			Body:       rw.body.Bytes(),
+		}
+
+		if err := m.idempotencyStore.Complete(r.Context(), key, record, idempotencyResponseTTL); err != nil {
+			slog.Error("failed to save idempotent response", "err", err)
+			return
+		}
+
+		completed = true
+	})
+}
+
+func replayResponse(w http.ResponseWriter, response *cache.IdempotentResponse) {
+	header := w.Header()
+	maps.Copy(header, response.Header)
+	header.Set("Idempotent-Replayed", "true")
+
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+}
+
+// recordingResponseWriter writes the response through while keeping a copy
+// of its status, headers and body.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	header      http.Header
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingResponseWriter) WriteHeader(statusCode int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	rw.statusCode = statusCode
+	rw.header = rw.Header().Clone()
+
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	rw.body.Write(b)
+
+	return rw.ResponseWriter.Write(b)
+}
@@ -1,11 +1,32 @@
 package middleware
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
+// limitedBody is a request body cut off by LimitBodySize, keeping the body it
+// wraps so that a later limit replaces this one instead of stacking on it.
+type limitedBody struct {
+	io.ReadCloser
+	original io.ReadCloser
+}
+
+// LimitBodySize cuts request bodies off after maxBytes; reading past it fails
+// with *http.MaxBytesError. A route raising the limit set for the whole API
+// replaces it, as long as nothing read the body in between.
 func LimitBodySize(maxBytes int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			body := r.Body
+			if limited, ok := body.(*limitedBody); ok {
+				body = limited.original
+			}
+
+			r.Body = &limitedBody{
+				ReadCloser: http.MaxBytesReader(w, body, maxBytes),
+				original:   body,
+			}
 
 			next.ServeHTTP(w, r)
 		})
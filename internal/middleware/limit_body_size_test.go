@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimitBodySizeIsReplacedByLaterLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  []int64
+		size    int
+		tooLong bool
+	}{
+		{"within limit", []int64{8}, 8, false},
+		{"past limit", []int64{8}, 9, true},
+		{"raised by route", []int64{8, 16}, 16, false},
+		{"past raised limit", []int64{8, 16}, 17, true},
+		{"lowered by route", []int64{16, 8}, 9, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var readErr error
+
+			var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, readErr = io.ReadAll(r.Body)
+			})
+			for i := len(tt.limits) - 1; i >= 0; i-- {
+				h = LimitBodySize(tt.limits[i])(h)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", tt.size)))
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			var maxBytesErr *http.MaxBytesError
+			if got := errors.As(readErr, &maxBytesErr); got != tt.tooLong {
+				t.Errorf("body cut off = %v, want %v (err %v)", got, tt.tooLong, readErr)
+			}
+		})
+	}
+}
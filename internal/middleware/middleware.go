@@ -9,13 +9,19 @@ import (
 
 type Middlewares interface {
 	AuthMiddleware(next http.Handler) http.Handler
+	IdempotencyMiddleware(next http.Handler) http.Handler
 }
 
 type middlewares struct {
-	repositories *repository.Repository
-	sessionStore cache.SessionStore
+	repositories     *repository.Repository
+	sessionStore     cache.SessionStore
+	idempotencyStore cache.IdempotencyStore
 }
 
-func NewMiddlewares(repositories *repository.Repository, sessionStore cache.SessionStore) Middlewares {
-	return &middlewares{repositories, sessionStore}
+func NewMiddlewares(
+	repositories *repository.Repository,
+	sessionStore cache.SessionStore,
+	idempotencyStore cache.IdempotencyStore,
+) Middlewares {
+	return &middlewares{repositories, sessionStore, idempotencyStore}
 }
@@ -0,0 +1,92 @@
+// Package notification turns board events into notifications for the users
+// watching the board or list and pushes them to their open connections.
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/event"
+	"github.com/mithileshgupta12/velaris/internal/outbox"
+)
+
+// Events lists the events that notify. A board's creation only concerns its
+// creator, and the users interested in a deleted board went with it.
+var Events = []string{
+	event.BoardUpdated,
+	event.BoardArchived,
+	event.BoardUnarchived,
+	event.ListCreated,
+	event.ListUpdated,
+	event.ListDeleted,
+	event.ListArchived,
+	event.ListUnarchived,
+}
+
+// ConsumerGroup is the event stream consumer group creating notifications.
+const ConsumerGroup = "notifications"
+
+// HandleEvent notifies the users watching the board or list of e and publishes
+// the notifications to their open connections. It is the handler of the
+// ConsumerGroup consumer.
+func HandleEvent(notificationRepository repository.NotificationRepository, notificationBroker cache.NotificationBroker) outbox.Handler {
+	return func(ctx context.Context, e *event.Event) error {
+		if !slices.Contains(Events, e.Name) {
+			return nil
+		}
+
+		listId, err := eventListId(e)
+		if err != nil {
+			return err
+		}
+
+		notifications, err := notificationRepository.CreateNotificationsForEvent(&repository.CreateNotificationsForEventArgs{
+			EventId: e.Id,
+			Event:   e.Name,
+			BoardId: e.BoardId,
+			ListId:  listId,
+			ActorId: e.ActorId,
+			Data:    string(e.Data),
+		})
+		if err != nil {
+			return err
+		}
+
+		// The notifications are saved; a client that misses the push sees
+		// them the next time it lists them.
+		for _, notification := range notifications {
+			data, err := json.Marshal(notification)
+			if err != nil {
+				return err
+			}
+
+			if err := notificationBroker.Publish(ctx, notification.UserId, string(data)); err != nil {
+				slog.Error("failed to publish notification", "notification_id", notification.Id, "err", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// eventListId returns the id of the list a list event is about, and zero for
+// board events.
+func eventListId(e *event.Event) (int64, error) {
+	if !strings.HasPrefix(e.Name, "list.") {
+		return 0, nil
+	}
+
+	var list struct {
+		Id int64 `json:"id"`
+	}
+	if err := json.Unmarshal(e.Data, &list); err != nil {
+		return 0, err
+	}
+
+	return list.Id, nil
+}
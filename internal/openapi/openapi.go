@@ -0,0 +1,328 @@
+// Package openapi builds the OpenAPI 3.1 description of the API from the Go
+// types handlers decode and encode.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower case HTTP methods to the operations of a path.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Op describes an endpoint for Builder.Add. Request and Response are
+// values of the Go types the handler decodes and encodes; Response is wrapped
+// in the success envelope shared by all JSON responses.
+type Op struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	// Auth marks endpoints behind the session cookie.
+	Auth bool
+	// Deprecated marks endpoints answered with Deprecation headers.
+	Deprecated bool
+	Query      []*Parameter
+	// Headers lists request headers the endpoint reads, such as If-Match.
+	Headers []*Parameter
+	// Request is the decoded body, or a *Schema for bodies without a Go type.
+	Request any
+	// RequestTypes overrides the media types of the request body.
+	RequestTypes []string
+	Status       int
+	Response     any
+	// Paginated adds next_cursor to the envelope.
+	Paginated bool
+	// Produces replaces the JSON envelope with a raw body of these media
+	// types, for downloads and pages.
+	Produces []string
+}
+
+// QueryParam describes a query parameter of type typ, e.g. "string".
+func QueryParam(name, typ, description string) *Parameter {
+	return &Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: typ},
+	}
+}
+
+// HeaderParam describes an optional request header.
+func HeaderParam(name, description string) *Parameter {
+	return &Parameter{
+		Name:        name,
+		In:          "header",
+		Description: description,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+const sessionScheme = "session"
+
+// Builder assembles a Document.
+type Builder struct {
+	doc     *Document
+	schemas *schemaRegistry
+	errors  *Schema
+	problem *Schema
+}
+
+// NewBuilder starts a document. errorResponse and problemResponse are the
+// bodies of error responses in the JSON envelope and as problem documents.
+func NewBuilder(info Info, errorResponse, problemResponse any) *Builder {
+	b := &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]*PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				SecuritySchemes: map[string]*SecurityScheme{
+					sessionScheme: {Type: "apiKey", In: "cookie", Name: "auth_session"},
+				},
+			},
+		},
+	}
+	b.schemas = newSchemaRegistry(b.doc.Components.Schemas)
+	b.errors = b.schemas.schemaOf(errorResponse)
+	b.problem = b.schemas.schemaOf(problemResponse)
+
+	return b
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)(?::[^}]*)?\}`)
+
+// Add describes op in the document.
+func (b *Builder) Add(op Op) {
+	method := strings.ToLower(op.Method)
+	path := pathParamPattern.ReplaceAllString(op.Path, "{$1}")
+
+	operation := &Operation{
+		OperationId: operationId(method, path),
+		Summary:     op.Summary,
+		Responses:   map[string]*Response{},
+		Deprecated:  op.Deprecated,
+	}
+
+	if op.Tag != "" {
+		operation.Tags = []string{op.Tag}
+	}
+
+	if op.Auth {
+		operation.Security = []map[string][]string{{sessionScheme: {}}}
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		schema := &Schema{Type: "string"}
+		if strings.HasSuffix(strings.ToLower(match[1]), "id") {
+			schema = &Schema{Type: "integer", Format: "int64", Minimum: ptr(1)}
+		}
+
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	operation.Parameters = append(operation.Parameters, op.Query...)
+	operation.Parameters = append(operation.Parameters, op.Headers...)
+
+	if op.Request != nil {
+		requestTypes := op.RequestTypes
+		if len(requestTypes) == 0 {
+			requestTypes = []string{"application/json"}
+		}
+
+		schema, ok := op.Request.(*Schema)
+		if !ok {
+			schema = b.schemas.schemaOf(op.Request)
+		}
+
+		operation.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{}}
+		for _, requestType := range requestTypes {
+			operation.RequestBody.Content[requestType] = &MediaType{Schema: schema}
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	response := &Response{Description: http.StatusText(status)}
+	switch {
+	case len(op.Produces) > 0:
+		response.Content = map[string]*MediaType{}
+		for _, mediaType := range op.Produces {
+			response.Content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+		}
+	case op.Response != nil:
+		response.Content = map[string]*MediaType{
+			"application/json": {Schema: b.envelope(op.Response, op.Paginated)},
+		}
+	}
+	operation.Responses[fmt.Sprint(status)] = response
+
+	operation.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]*MediaType{
+			"application/json":         {Schema: b.errors},
+			"application/problem+json": {Schema: b.problem},
+		},
+	}
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	(*item)[method] = operation
+}
+
+// Schema returns the schema of the Go type of v, registering named structs as
+// components.
+func (b *Builder) Schema(v any) *Schema {
+	return b.schemas.schemaOf(v)
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+func (b *Builder) envelope(data any, paginated bool) *Schema {
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"data":    b.schemas.schemaOf(data),
+		},
+		Required: []string{"success", "data"},
+	}
+
+	if paginated {
+		envelope.Properties["next_cursor"] = &Schema{
+			Type:        "string",
+			Description: "Cursor of the next page, absent on the last page.",
+		}
+	}
+
+	return envelope
+}
+
+// operationId derives a stable id such as "get_boards_id_lists" from an
+// operation's method and path.
+func operationId(method, path string) string {
+	parts := []string{method}
+	for segment := range strings.SplitSeq(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		segment = strings.NewReplacer(".", "_", "-", "_").Replace(segment)
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// MissingRoutes lists the routes of router, as "METHOD /path", that doc does
+// not describe.
+func MissingRoutes(router chi.Routes, doc *Document) ([]string, error) {
+	missing := []string{}
+
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if method == http.MethodOptions || method == http.MethodHead {
+			return nil
+		}
+
+		path := pathParamPattern.ReplaceAllString(route, "{$1}")
+		if len(path) > 1 {
+			path = strings.TrimSuffix(path, "/")
+		}
+
+		if item, ok := doc.Paths[path]; ok {
+			if _, ok := (*item)[strings.ToLower(method)]; ok {
+				return nil
+			}
+		}
+
+		missing = append(missing, method+" "+path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
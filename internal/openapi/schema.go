@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema the API description uses. Type is a
+// string, or a list of strings for nullable values.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// Object returns an object schema with the given properties, for bodies that
+// have no Go type of their own.
+func Object(properties map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: properties}
+}
+
+// Nullable allows null in place of the value of s.
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+
+	nullable := *s
+	if typ, ok := s.Type.(string); ok {
+		nullable.Type = []string{typ, "null"}
+	}
+
+	return &nullable
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaRegistry turns Go types into schemas. Named structs are described
+// once under components and referenced everywhere else.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry(schemas map[string]*Schema) *schemaRegistry {
+	return &schemaRegistry{
+		schemas: schemas,
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (sr *schemaRegistry) schemaOf(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return sr.schemaOfType(reflect.TypeOf(v))
+}
+
+func (sr *schemaRegistry) schemaOfType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Nullable(sr.schemaOfType(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: sr.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: sr.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sr.structSchema(t)
+		}
+
+		return sr.ref(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// ref registers the named struct t under components and returns a reference
+// to it.
+func (sr *schemaRegistry) ref(t reflect.Type) *Schema {
+	name, ok := sr.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := sr.schemas[name]; taken {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+
+		sr.names[t] = name
+		sr.schemas[name] = &Schema{}
+		*sr.schemas[name] = *sr.structSchema(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (sr *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := sr.structSchema(field.Type)
+			for propertyName, property := range embedded.Properties {
+				schema.Properties[propertyName] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = sr.schemaOfType(field.Type)
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/event"
+)
+
+const (
+	consumeBatchSize = 50
+	consumeBlock     = 5 * time.Second
+	// claimIdle is how long a message may stay unacknowledged by a consumer
+	// before another consumer of the group takes it over.
+	claimIdle = time.Minute
+	// retryDelay is the pause after a handler failed, before the failed
+	// event is handled again.
+	retryDelay = 5 * time.Second
+	// maxHandleAttempts is the number of failed attempts at handling an
+	// event after which it is dead-lettered, so that an event failing for
+	// good does not hold back its group forever.
+	maxHandleAttempts = 10
+)
+
+// Handler handles an event. Returning an error leaves the event pending, to
+// be handled again, and holds back the events after it so that they are
+// handled in order. After maxHandleAttempts failures the event is moved to
+// the dead-letter stream of the group instead.
+type Handler func(ctx context.Context, e *event.Event) error
+
+// Consumer feeds the events of the stream to a handler as a member of a
+// consumer group. Each group keeps its own position in the stream, so every
+// feature subscribes under its own group name and receives every event
+// published after the group was created, independently of the others.
+type Consumer struct {
+	eventStream cache.EventStream
+	group       string
+	name        string
+	handler     Handler
+	// failures counts the failed attempts at the pending events by stream
+	// entry id. A restart starts the count over.
+	failures map[string]int
+}
+
+// NewConsumer returns a consumer of group. Processes sharing a group split
+// its events between them.
+func NewConsumer(eventStream cache.EventStream, group string, handler Handler) *Consumer {
+	hostname, _ := os.Hostname()
+
+	return &Consumer{
+		eventStream: eventStream,
+		group:       group,
+		name:        fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		handler:     handler,
+		failures:    map[string]int{},
+	}
+}
+
+// Run handles events until ctx is done.
+func (c *Consumer) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := c.consume(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("failed to consume events", "group", c.group, "err", err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// consume handles the events left pending by an earlier failure or by dead
+// consumers, then waits for new ones. It returns on the first error.
+func (c *Consumer) consume(ctx context.Context) error {
+	if err := c.eventStream.CreateGroup(ctx, c.group); err != nil {
+		return err
+	}
+
+	if err := c.eventStream.Claim(ctx, c.group, c.name, claimIdle, consumeBatchSize); err != nil {
+		return err
+	}
+
+	for {
+		messages, err := c.eventStream.Pending(ctx, c.group, c.name, consumeBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		if err := c.handle(ctx, messages); err != nil {
+			return err
+		}
+	}
+
+	for ctx.Err() == nil {
+		messages, err := c.eventStream.Read(ctx, c.group, c.name, consumeBatchSize, consumeBlock)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			// Take over what dead consumers left while the stream was idle.
+			return nil
+		}
+
+		if err := c.handle(ctx, messages); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Consumer) handle(ctx context.Context, messages []cache.StreamMessage) error {
+	for _, message := range messages {
+		e := &event.Event{}
+
+		// Entries trimmed from the stream come back empty and malformed ones
+		// would fail forever; both are dropped.
+		if err := json.Unmarshal([]byte(message.Data), e); err != nil {
+			slog.Error("dropping undecodable event", "group", c.group, "message_id", message.Id, "err", err)
+		} else if err := c.handler(ctx, e); err != nil {
+			// Failures from shutting down are not the event's fault.
+			if ctx.Err() == nil {
+				c.failures[message.Id]++
+			}
+			if c.failures[message.Id] < maxHandleAttempts {
+				return fmt.Errorf("event %d: %w", e.Id, err)
+			}
+
+			slog.Error("dead-lettering event", "group", c.group, "message_id", message.Id, "event_id", e.Id, "err", err)
+
+			if err := c.eventStream.DeadLetter(ctx, c.group, message, err.Error()); err != nil {
+				return err
+			}
+		}
+
+		delete(c.failures, message.Id)
+
+		if err := c.eventStream.Ack(ctx, c.group, message.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
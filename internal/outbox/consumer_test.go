@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/event"
+)
+
+// fakeEventStream records the entries acknowledged and dead-lettered.
+type fakeEventStream struct {
+	cache.EventStream
+
+	acked        []string
+	deadLettered []string
+}
+
+func (f *fakeEventStream) Ack(ctx context.Context, group string, ids ...string) error {
+	f.acked = append(f.acked, ids...)
+
+	return nil
+}
+
+func (f *fakeEventStream) DeadLetter(ctx context.Context, group string, message cache.StreamMessage, reason string) error {
+	f.deadLettered = append(f.deadLettered, message.Id)
+
+	return nil
+}
+
+func TestHandleDeadLettersEventsFailingForGood(t *testing.T) {
+	stream := &fakeEventStream{}
+	consumer := NewConsumer(stream, "test", func(ctx context.Context, e *event.Event) error {
+		if e.Id == 1 {
+			return errors.New("constraint violated")
+		}
+
+		return nil
+	})
+
+	messages := []cache.StreamMessage{
+		{Id: "1-0", Data: `{"id":1,"name":"board.updated"}`},
+		{Id: "2-0", Data: `{"id":2,"name":"board.updated"}`},
+	}
+
+	for attempt := 1; attempt < maxHandleAttempts; attempt++ {
+		if err := consumer.handle(context.Background(), messages); err == nil {
+			t.Fatalf("attempt %d succeeded, want the handler error", attempt)
+		}
+		if len(stream.acked) != 0 {
+			t.Fatalf("attempt %d acknowledged %v, want the events held back", attempt, stream.acked)
+		}
+	}
+
+	if err := consumer.handle(context.Background(), messages); err != nil {
+		t.Fatalf("last attempt: %v", err)
+	}
+
+	if len(stream.deadLettered) != 1 || stream.deadLettered[0] != "1-0" {
+		t.Errorf("dead-lettered %v, want [1-0]", stream.deadLettered)
+	}
+	if len(stream.acked) != 2 {
+		t.Errorf("acknowledged %v, want both events", stream.acked)
+	}
+	if len(consumer.failures) != 0 {
+		t.Errorf("failures = %v, want none left", consumer.failures)
+	}
+}
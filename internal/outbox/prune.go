@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+)
+
+// retention is how long published events are kept in the outbox, for
+// inspection, before they are pruned.
+const retention = 7 * 24 * time.Hour
+
+// RegisterPruneJob registers the hourly job deleting the events published
+// longer than retention ago.
+func RegisterPruneJob(queue *jobs.Queue, outboxRepository repository.OutboxRepository) *jobs.Job[struct{}] {
+	job := jobs.Register(queue, "outbox.prune", jobs.Options{}, func(ctx context.Context, _ struct{}) error {
+		deleted, err := outboxRepository.DeletePublishedOutboxEvents(&repository.DeletePublishedOutboxEventsArgs{
+			PublishedBefore: time.Now().Add(-retention),
+		})
+		if err != nil {
+			return err
+		}
+
+		if deleted > 0 {
+			slog.Info("pruned outbox", "deleted", deleted)
+		}
+
+		return nil
+	})
+
+	job.Schedule("@hourly", struct{}{})
+
+	return job
+}
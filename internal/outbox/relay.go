@@ -0,0 +1,95 @@
+// Package outbox publishes the domain events repositories record in the
+// outbox table to the event stream, and lets features subscribe to them.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/event"
+)
+
+const (
+	relayInterval  = 500 * time.Millisecond
+	relayBatchSize = 100
+)
+
+// Relay moves events from the outbox to the event stream. An event is marked
+// published only after the stream accepted it, so a crash in between
+// publishes it again: subscribers see every event at least once.
+type Relay struct {
+	outboxRepository repository.OutboxRepository
+	eventStream      cache.EventStream
+}
+
+func NewRelay(outboxRepository repository.OutboxRepository, eventStream cache.EventStream) *Relay {
+	return &Relay{outboxRepository, eventStream}
+}
+
+// Run relays events until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going without waiting while there is a backlog.
+		for {
+			published, err := r.RelayPending(ctx)
+			if err != nil {
+				slog.Error("failed to relay outbox events", "err", err)
+			}
+			if err != nil || published < relayBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes the oldest batch of unpublished events and returns
+// how many it published.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	return r.outboxRepository.PublishOutboxEvents(&repository.PublishOutboxEventsArgs{
+		Limit: relayBatchSize,
+		Publish: func(outboxEvents []*models.OutboxEvent) error {
+			data := make([]string, 0, len(outboxEvents))
+
+			for _, outboxEvent := range outboxEvents {
+				encoded, err := json.Marshal(toEvent(outboxEvent))
+				if err != nil {
+					return err
+				}
+
+				data = append(data, string(encoded))
+			}
+
+			return r.eventStream.Publish(ctx, data)
+		},
+	})
+}
+
+func toEvent(outboxEvent *models.OutboxEvent) *event.Event {
+	e := &event.Event{
+		Id:         outboxEvent.Id,
+		Name:       outboxEvent.Event,
+		BoardId:    outboxEvent.BoardId,
+		Data:       json.RawMessage(outboxEvent.Payload),
+		OccurredAt: outboxEvent.CreatedAt,
+	}
+
+	if outboxEvent.ActorId != nil {
+		e.ActorId = *outboxEvent.ActorId
+	}
+
+	return e
+}
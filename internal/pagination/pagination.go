@@ -0,0 +1,75 @@
+// Package pagination holds the page queries and cursors shared by the
+// handlers parsing them and the repositories applying them.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+)
+
+var ErrInvalidCursor = apperror.New(apperror.KindInvalid, "invalid_cursor", "cursor is invalid")
+
+// FilterOp is the comparison of a Filter. Filters are written as query
+// parameters, "name=x" for FilterEquals and "name~=x" for FilterContains.
+type FilterOp string
+
+const (
+	FilterEquals   FilterOp = "="
+	FilterContains FilterOp = "~="
+)
+
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value string
+}
+
+// Query is the parsed form of the limit, cursor, sort and filter query
+// parameters shared by collection endpoints. A Limit of 0 returns every item
+// after the cursor.
+type Query struct {
+	Limit   int
+	Sort    string
+	Desc    bool
+	Cursor  *Cursor
+	Filters []Filter
+}
+
+// Cursor marks the last item of a page. It is handed to clients as an opaque
+// string and only valid for the sort order it was created with.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int64  `json:"i"`
+}
+
+// SortParam returns the sort as written in the query string, e.g. "-name".
+func (q *Query) SortParam() string {
+	if q.Desc {
+		return "-" + q.Sort
+	}
+
+	return q.Sort
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -3,18 +3,20 @@ package route
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
 	"github.com/mithileshgupta12/velaris/internal/db/repository"
 	"github.com/mithileshgupta12/velaris/internal/handler"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
 )
 
 func AuthRoutes(
-	r *chi.Mux,
-	userRepository repository.UserRepository,
+	r chi.Router,
+	app *config.AppFlags,
+	repositories *repository.Repository,
 	sessionStore cache.SessionStore,
 	middlewares middleware.Middlewares,
 ) {
-	authHandler := handler.NewAuthHandler(userRepository, sessionStore)
+	authHandler := handler.NewAuthHandler(repositories, sessionStore, app.Key)
 
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/register", authHandler.Register)
@@ -22,6 +24,7 @@ func AuthRoutes(
 
 		r.Group(func(r chi.Router) {
 			r.Use(middlewares.AuthMiddleware)
+			r.Use(middlewares.IdempotencyMiddleware)
 
 			r.Post("/logout", authHandler.Logout)
 			r.Get("/user", authHandler.GetLoggedInUser)
@@ -0,0 +1,25 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func BatchRoutes(
+	r chi.Router,
+	api http.Handler,
+	transact handler.Transactor,
+	middlewares middleware.Middlewares,
+) {
+	batchHandler := handler.NewBatchHandler(api, transact)
+
+	r.Route("/batch", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Post("/", batchHandler.Run)
+	})
+}
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func BoardMemberRoutes(
+	r chi.Router,
+	app *config.AppFlags,
+	boardMemberRepository repository.BoardMemberRepository,
+	boardInvitationRepository repository.BoardInvitationRepository,
+	boardPolicy policy.BoardPolicy,
+	middlewares middleware.Middlewares,
+) {
+	boardMemberHandler := handler.NewBoardMemberHandler(boardMemberRepository, boardInvitationRepository, boardPolicy, app.Key)
+
+	r.Route("/boards/{boardId}/members", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Get("/", boardMemberHandler.Members)
+		r.Delete("/{userId}", boardMemberHandler.RemoveMember)
+	})
+
+	r.Route("/boards/{boardId}/invitations", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Get("/", boardMemberHandler.Invitations)
+		r.Post("/", boardMemberHandler.Invite)
+		r.Delete("/{id}", boardMemberHandler.Revoke)
+	})
+
+	r.Route("/invitations", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Post("/accept", boardMemberHandler.Accept)
+	})
+}
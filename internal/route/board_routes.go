@@ -9,21 +9,44 @@ import (
 )
 
 func BoardRoutes(
-	r *chi.Mux,
+	r chi.Router,
 	boardRepository repository.BoardRepository,
+	listRepository repository.ListRepository,
+	watcherRepository repository.WatcherRepository,
 	boardPolicy policy.Policy,
 	middlewares middleware.Middlewares,
 ) {
-	boardHandler := handler.NewBoardHandler(boardRepository, boardPolicy)
+	boardHandler := handler.NewBoardHandler(boardRepository, listRepository, watcherRepository, boardPolicy)
 
 	r.Route("/boards", func(r chi.Router) {
 		r.Use(middlewares.AuthMiddleware)
 
-		r.Get("/", boardHandler.Index)
-		r.Post("/", boardHandler.Store)
-		r.Get("/{id}", boardHandler.Show)
-		r.Put("/{id}", boardHandler.Update)
-		r.Delete("/{id}", boardHandler.Destroy)
-	})
+		// The larger limit of Trello imports must be in place before
+		// IdempotencyMiddleware reads the body.
+		r.With(
+			middleware.LimitBodySize(handler.TrelloImportMaxBytes),
+			middlewares.IdempotencyMiddleware,
+		).Post("/import/trello", boardHandler.ImportTrello)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middlewares.IdempotencyMiddleware)
 
+			r.Get("/", boardHandler.Index)
+			r.Post("/", boardHandler.Store)
+			r.Post("/import", boardHandler.Import)
+			r.Get("/{id}", boardHandler.Show)
+			r.Put("/{id}", boardHandler.Update)
+			r.Patch("/{id}", boardHandler.Patch)
+			r.Delete("/{id}", boardHandler.Destroy)
+			r.Post("/{id}/archive", boardHandler.Archive)
+			r.Post("/{id}/unarchive", boardHandler.Unarchive)
+			r.Post("/{id}/duplicate", boardHandler.Duplicate)
+			r.Post("/{id}/template", boardHandler.MarkAsTemplate)
+			r.Delete("/{id}/template", boardHandler.UnmarkAsTemplate)
+			r.Post("/{id}/watch", boardHandler.Watch)
+			r.Delete("/{id}/watch", boardHandler.Unwatch)
+			r.Get("/{id}/export", boardHandler.Export)
+			r.Get("/{id}/export.{format}", boardHandler.Export)
+		})
+	})
 }
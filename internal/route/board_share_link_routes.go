@@ -0,0 +1,33 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func BoardShareLinkRoutes(
+	r chi.Router,
+	boardShareLinkRepository repository.BoardShareLinkRepository,
+	boardRepository repository.BoardRepository,
+	boardPolicy policy.BoardPolicy,
+	attemptLimiter cache.AttemptLimiter,
+	middlewares middleware.Middlewares,
+) {
+	boardShareLinkHandler := handler.NewBoardShareLinkHandler(boardShareLinkRepository, boardRepository, boardPolicy, attemptLimiter)
+
+	r.Route("/boards/{boardId}/share-links", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Get("/", boardShareLinkHandler.Index)
+		r.Post("/", boardShareLinkHandler.Store)
+		r.Delete("/{id}", boardShareLinkHandler.Destroy)
+	})
+
+	// Share links are read without an account.
+	r.Get("/public/boards/{token}", boardShareLinkHandler.Public)
+}
@@ -0,0 +1,16 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/jobs"
+)
+
+// JobRoutes serves the status of the job queue at /jobs/status. It is
+// mounted by the worker on its own address, which is not meant to be
+// exposed publicly, rather than on the API.
+func JobRoutes(r chi.Router, queue *jobs.Queue) {
+	jobHandler := handler.NewJobHandler(queue)
+
+	r.Get("/jobs/status", jobHandler.Status)
+}
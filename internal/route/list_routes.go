@@ -9,21 +9,29 @@ import (
 )
 
 func ListRoutes(
-	r *chi.Mux,
+	r chi.Router,
 	listRepository repository.ListRepository,
+	boardRepository repository.BoardRepository,
+	watcherRepository repository.WatcherRepository,
 	boardPolicy policy.Policy,
 	listPolicy policy.Policy,
 	middlewares middleware.Middlewares,
 ) {
-	listHandler := handler.NewListHandler(listRepository, boardPolicy, listPolicy)
+	listHandler := handler.NewListHandler(listRepository, boardRepository, watcherRepository, boardPolicy, listPolicy)
 
 	r.Route("/boards/{boardId}/lists", func(r chi.Router) {
 		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
 
 		r.Get("/", listHandler.Index)
 		r.Post("/", listHandler.Store)
 		r.Get("/{id}", listHandler.Show)
 		r.Put("/{id}", listHandler.Update)
+		r.Patch("/{id}", listHandler.Patch)
 		r.Delete("/{id}", listHandler.Destroy)
+		r.Post("/{id}/archive", listHandler.Archive)
+		r.Post("/{id}/unarchive", listHandler.Unarchive)
+		r.Post("/{id}/watch", listHandler.Watch)
+		r.Delete("/{id}/watch", listHandler.Unwatch)
 	})
 }
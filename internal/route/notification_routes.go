@@ -0,0 +1,41 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func NotificationRoutes(
+	r chi.Router,
+	app *config.AppFlags,
+	notificationRepository repository.NotificationRepository,
+	digestRepository repository.DigestRepository,
+	notificationBroker cache.NotificationBroker,
+	middlewares middleware.Middlewares,
+) {
+	notificationHandler := handler.NewNotificationHandler(notificationRepository, notificationBroker)
+	digestHandler := handler.NewDigestHandler(digestRepository, app.Key, app.FrontendUrl)
+
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Get("/", notificationHandler.Index)
+		r.Get("/stream", notificationHandler.Stream)
+		r.Post("/read-all", notificationHandler.ReadAll)
+		r.Post("/{id}/read", notificationHandler.Read)
+		r.Get("/preferences", notificationHandler.Preferences)
+		r.Put("/preferences", notificationHandler.UpdatePreferences)
+		r.Get("/digest", digestHandler.Show)
+		r.Put("/digest", digestHandler.Update)
+	})
+
+	// Unsubscribe links in digests are signed instead of authenticated. Only
+	// POST unsubscribes; GET asks to confirm.
+	r.Get("/unsubscribe", digestHandler.ConfirmUnsubscribe)
+	r.Post("/unsubscribe", digestHandler.Unsubscribe)
+}
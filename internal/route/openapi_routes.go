@@ -0,0 +1,211 @@
+package route
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/helper"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+	"github.com/mithileshgupta12/velaris/internal/openapi"
+	"github.com/mithileshgupta12/velaris/internal/transfer"
+)
+
+// docsPage loads a pinned Redoc release, so the page only changes when the
+// version here does.
+const docsPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Velaris API</title>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+  </head>
+  <body>
+    <redoc spec-url="/openapi.json"></redoc>
+    <script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js" crossorigin="anonymous"></script>
+  </body>
+</html>
+`
+
+// OpenAPIRoutes serves the API description at /openapi.json and a Redoc page
+// rendering it at /docs.
+func OpenAPIRoutes(r chi.Router, doc *openapi.Document) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		log.Panicf("failed to encode openapi document: %v", err)
+	}
+
+	r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+
+	r.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(docsPage))
+	})
+}
+
+// APIDocument describes every route registered by RegisterRoutes. A route
+// added there must be added here too; VerifyDocument reports the ones that
+// are not.
+func APIDocument() *openapi.Document {
+	b := openapi.NewBuilder(
+		openapi.Info{Title: "Velaris API", Version: "1.0.0"},
+		helper.ErrorResponse{},
+		helper.ProblemResponse{},
+	)
+
+	archived := openapi.QueryParam("archived", "boolean", "Return archived items instead of active ones.")
+	watching := openapi.QueryParam("watching", "boolean", "Return only the boards the user watches.")
+	dryRun := openapi.QueryParam("dry_run", "boolean", "Report what would be imported without creating anything.")
+	unsubscribeToken := openapi.QueryParam("token", "string", "Signed token of the unsubscribe link.")
+	sharePassword := openapi.HeaderParam(handler.SharePasswordHeader, "Password of the share link, when it has one.")
+	ifMatch := openapi.HeaderParam("If-Match", "ETag the change is based on; a stale ETag fails with 412.")
+	pageParams := func(sorts string) []*openapi.Parameter {
+		return []*openapi.Parameter{
+			openapi.QueryParam("limit", "integer", "Page size, 1 to 100. Every item is returned without it."),
+			openapi.QueryParam("cursor", "string", "next_cursor of the previous page."),
+			openapi.QueryParam("sort", "string", "One of "+sorts+", prefixed with - for descending order."),
+			openapi.QueryParam("name", "string", "Only items with exactly this name."),
+			openapi.QueryParam("name~", "string", "Only items whose name contains this text."),
+		}
+	}
+	include := func(relations string) *openapi.Parameter {
+		return openapi.QueryParam("include", "string", "Comma separated relations to load: "+relations+".")
+	}
+
+	boardPatch := openapi.Object(map[string]*openapi.Schema{
+		"name":        {Type: "string"},
+		"description": {Type: []string{"string", "null"}},
+	})
+	listPatch := openapi.Object(map[string]*openapi.Schema{
+		"name":     {Type: "string"},
+		"position": {Type: "integer"},
+	})
+	mergePatch := []string{"application/merge-patch+json", "application/json"}
+
+	exportTypes := []string{}
+	for _, format := range transfer.ExportFormats() {
+		exporter, _ := transfer.GetExporter(format)
+		exportTypes = append(exportTypes, exporter.ContentType())
+	}
+
+	// ops are the v1 routes. Each is also served without the version prefix
+	// until unversionedDeprecation's sunset.
+	ops := []openapi.Op{
+		{Method: "POST", Path: "/auth/register", Tag: "auth", Summary: "Register a user", Request: handler.RegisterUserRequest{}, Status: http.StatusCreated, Response: ""},
+		{Method: "POST", Path: "/auth/login", Tag: "auth", Summary: "Log in and start a session", Request: handler.LoginUserRequest{}, Response: middleware.CtxUser{}},
+		{Method: "POST", Path: "/auth/logout", Tag: "auth", Summary: "End the session", Auth: true, Response: ""},
+		{Method: "GET", Path: "/auth/user", Tag: "auth", Summary: "Get the logged in user", Auth: true, Response: middleware.CtxUser{}},
+
+		{Method: "GET", Path: "/boards", Tag: "boards", Summary: "List boards", Auth: true, Query: append(pageParams("name, created_at, updated_at"), archived, watching, include("lists, owner, watchers")), Response: []*models.Board{}, Paginated: true},
+		{Method: "POST", Path: "/boards", Tag: "boards", Summary: "Create a board", Auth: true, Request: handler.BoardRequest{}, Status: http.StatusCreated, Response: &models.Board{}},
+		{Method: "POST", Path: "/boards/import", Tag: "boards", Summary: "Import a board document", Auth: true, Request: transfer.Document{}, Status: http.StatusCreated, Response: &handler.ImportBoardResponse{}},
+		{Method: "POST", Path: "/boards/import/trello", Tag: "boards", Summary: "Import a Trello board export", Auth: true, Query: []*openapi.Parameter{dryRun}, Request: &openapi.Schema{}, RequestTypes: []string{"application/json", "multipart/form-data"}, Status: http.StatusCreated, Response: &transfer.TrelloImportReport{}},
+		{Method: "GET", Path: "/boards/{id}", Tag: "boards", Summary: "Get a board", Auth: true, Query: []*openapi.Parameter{include("lists, owner, watchers")}, Response: &models.Board{}},
+		{Method: "PUT", Path: "/boards/{id}", Tag: "boards", Summary: "Replace a board", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Request: handler.BoardRequest{}, Response: &models.Board{}},
+		{Method: "PATCH", Path: "/boards/{id}", Tag: "boards", Summary: "Update some fields of a board", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Request: boardPatch, RequestTypes: mergePatch, Response: &models.Board{}},
+		{Method: "DELETE", Path: "/boards/{id}", Tag: "boards", Summary: "Delete a board", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/boards/{id}/archive", Tag: "boards", Summary: "Archive a board", Auth: true, Response: &models.Board{}},
+		{Method: "POST", Path: "/boards/{id}/unarchive", Tag: "boards", Summary: "Unarchive a board", Auth: true, Response: &models.Board{}},
+		{Method: "POST", Path: "/boards/{id}/duplicate", Tag: "boards", Summary: "Duplicate a board with its lists", Auth: true, Request: handler.DuplicateBoardRequest{}, Status: http.StatusCreated, Response: &models.Board{}},
+		{Method: "POST", Path: "/boards/{id}/template", Tag: "boards", Summary: "Mark a board as template", Auth: true, Response: &models.Board{}},
+		{Method: "DELETE", Path: "/boards/{id}/template", Tag: "boards", Summary: "Unmark a board as template", Auth: true, Response: &models.Board{}},
+		{Method: "POST", Path: "/boards/{id}/watch", Tag: "boards", Summary: "Watch a board", Auth: true, Status: http.StatusNoContent},
+		{Method: "DELETE", Path: "/boards/{id}/watch", Tag: "boards", Summary: "Stop watching a board", Auth: true, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/boards/{id}/export", Tag: "boards", Summary: "Export a board as JSON", Auth: true, Produces: []string{"application/json"}},
+		{Method: "GET", Path: "/boards/{id}/export.{format}", Tag: "boards", Summary: "Export a board in a registered format", Auth: true, Produces: exportTypes},
+
+		{Method: "GET", Path: "/boards/{boardId}/lists", Tag: "lists", Summary: "List the lists of a board", Auth: true, Query: append(pageParams("position, name, created_at, updated_at"), archived, include("board")), Response: []*models.List{}, Paginated: true},
+		{Method: "POST", Path: "/boards/{boardId}/lists", Tag: "lists", Summary: "Create a list", Auth: true, Request: handler.ListRequest{}, Status: http.StatusCreated, Response: &models.List{}},
+		{Method: "GET", Path: "/boards/{boardId}/lists/{id}", Tag: "lists", Summary: "Get a list", Auth: true, Query: []*openapi.Parameter{include("board")}, Response: &models.List{}},
+		{Method: "PUT", Path: "/boards/{boardId}/lists/{id}", Tag: "lists", Summary: "Replace a list", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Request: handler.ListRequest{}, Response: &models.List{}},
+		{Method: "PATCH", Path: "/boards/{boardId}/lists/{id}", Tag: "lists", Summary: "Update some fields of a list", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Request: listPatch, RequestTypes: mergePatch, Response: &models.List{}},
+		{Method: "DELETE", Path: "/boards/{boardId}/lists/{id}", Tag: "lists", Summary: "Delete a list", Auth: true, Headers: []*openapi.Parameter{ifMatch}, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/boards/{boardId}/lists/{id}/archive", Tag: "lists", Summary: "Archive a list", Auth: true, Response: &models.List{}},
+		{Method: "POST", Path: "/boards/{boardId}/lists/{id}/unarchive", Tag: "lists", Summary: "Unarchive a list", Auth: true, Response: &models.List{}},
+		{Method: "POST", Path: "/boards/{boardId}/lists/{id}/watch", Tag: "lists", Summary: "Watch a list", Auth: true, Status: http.StatusNoContent},
+		{Method: "DELETE", Path: "/boards/{boardId}/lists/{id}/watch", Tag: "lists", Summary: "Stop watching a list", Auth: true, Status: http.StatusNoContent},
+
+		{Method: "GET", Path: "/templates", Tag: "templates", Summary: "List board templates", Auth: true, Response: []*models.Template{}},
+
+		{Method: "GET", Path: "/search", Tag: "search", Summary: "Search boards and lists", Auth: true, Query: []*openapi.Parameter{
+			openapi.QueryParam("q", "string", "Words to search for, matched as prefixes."),
+			openapi.QueryParam("page", "integer", "Page number, starting at 1."),
+			openapi.QueryParam("limit", "integer", "Page size, 1 to 100."),
+		}, Response: &handler.SearchResponse{}},
+
+		{Method: "GET", Path: "/boards/{boardId}/members", Tag: "members", Summary: "List the owner and members of a board", Auth: true, Response: []*models.BoardMember{}},
+		{Method: "DELETE", Path: "/boards/{boardId}/members/{userId}", Tag: "members", Summary: "Remove a member from a board, or leave it", Auth: true, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/boards/{boardId}/invitations", Tag: "members", Summary: "List the pending invitations to a board", Auth: true, Response: []*models.BoardInvitation{}},
+		{Method: "POST", Path: "/boards/{boardId}/invitations", Tag: "members", Summary: "Invite an email to a board", Auth: true, Request: handler.BoardInvitationRequest{}, Status: http.StatusCreated, Response: &models.BoardInvitation{}},
+		{Method: "DELETE", Path: "/boards/{boardId}/invitations/{id}", Tag: "members", Summary: "Revoke an invitation", Auth: true, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/invitations/accept", Tag: "members", Summary: "Accept an invitation with the token of its link", Auth: true, Request: handler.AcceptBoardInvitationRequest{}, Response: &models.BoardMember{}},
+
+		{Method: "GET", Path: "/boards/{boardId}/share-links", Tag: "sharing", Summary: "List the share links of a board", Auth: true, Response: []*models.BoardShareLink{}},
+		{Method: "POST", Path: "/boards/{boardId}/share-links", Tag: "sharing", Summary: "Create a read-only share link to a board", Auth: true, Request: handler.BoardShareLinkRequest{}, Status: http.StatusCreated, Response: &models.BoardShareLink{}},
+		{Method: "DELETE", Path: "/boards/{boardId}/share-links/{id}", Tag: "sharing", Summary: "Revoke a share link", Auth: true, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/public/boards/{token}", Tag: "sharing", Summary: "View a shared board without an account", Headers: []*openapi.Parameter{sharePassword}, Response: &handler.PublicBoardResponse{}},
+
+		{Method: "GET", Path: "/boards/{boardId}/webhooks", Tag: "webhooks", Summary: "List the webhooks of a board", Auth: true, Response: []*models.Webhook{}},
+		{Method: "POST", Path: "/boards/{boardId}/webhooks", Tag: "webhooks", Summary: "Create a webhook", Auth: true, Request: handler.WebhookRequest{}, Status: http.StatusCreated, Response: &models.Webhook{}},
+		{Method: "GET", Path: "/boards/{boardId}/webhooks/{id}", Tag: "webhooks", Summary: "Get a webhook", Auth: true, Response: &models.Webhook{}},
+		{Method: "DELETE", Path: "/boards/{boardId}/webhooks/{id}", Tag: "webhooks", Summary: "Delete a webhook", Auth: true, Status: http.StatusNoContent},
+		{Method: "GET", Path: "/boards/{boardId}/webhooks/{id}/deliveries", Tag: "webhooks", Summary: "List the deliveries of a webhook with their attempts", Auth: true, Query: []*openapi.Parameter{
+			openapi.QueryParam("limit", "integer", "Page size, 1 to 100."),
+			openapi.QueryParam("cursor", "string", "next_cursor of the previous page."),
+			openapi.QueryParam("sort", "string", "created_at, prefixed with - for descending order. Defaults to -created_at."),
+			openapi.QueryParam("event", "string", "Only deliveries of this event."),
+			openapi.QueryParam("status", "string", "Only deliveries with this status: pending, succeeded or dead."),
+		}, Response: []*models.WebhookDelivery{}, Paginated: true},
+
+		{Method: "GET", Path: "/notifications", Tag: "notifications", Summary: "List notifications with the unread count", Auth: true, Query: []*openapi.Parameter{
+			openapi.QueryParam("limit", "integer", "Page size, 1 to 100."),
+			openapi.QueryParam("cursor", "string", "next_cursor of the previous page."),
+			openapi.QueryParam("sort", "string", "created_at, prefixed with - for descending order. Defaults to -created_at."),
+			openapi.QueryParam("event", "string", "Only notifications of this event."),
+			openapi.QueryParam("unread", "boolean", "Only notifications not read yet."),
+		}, Response: &handler.NotificationsResponse{}, Paginated: true},
+		{Method: "GET", Path: "/notifications/stream", Tag: "notifications", Summary: "Receive new notifications as server-sent events", Auth: true, Produces: []string{"text/event-stream"}},
+		{Method: "POST", Path: "/notifications/read-all", Tag: "notifications", Summary: "Mark every notification read", Auth: true, Status: http.StatusNoContent},
+		{Method: "POST", Path: "/notifications/{id}/read", Tag: "notifications", Summary: "Mark a notification read", Auth: true, Response: &models.Notification{}},
+		{Method: "GET", Path: "/notifications/preferences", Tag: "notifications", Summary: "List which events notify", Auth: true, Response: []*models.NotificationPreference{}},
+		{Method: "PUT", Path: "/notifications/preferences", Tag: "notifications", Summary: "Turn the notifications of events on or off", Auth: true, Request: handler.NotificationPreferencesRequest{}, Response: []*models.NotificationPreference{}},
+
+		{Method: "GET", Path: "/notifications/digest", Tag: "notifications", Summary: "Get how often digest emails are sent", Auth: true, Response: &models.DigestSetting{}},
+		{Method: "PUT", Path: "/notifications/digest", Tag: "notifications", Summary: "Set how often digest emails are sent", Auth: true, Request: handler.DigestRequest{}, Response: &models.DigestSetting{}},
+		{Method: "GET", Path: "/unsubscribe", Tag: "notifications", Summary: "Ask to confirm turning digest emails off from an unsubscribe link", Query: []*openapi.Parameter{unsubscribeToken}, Produces: []string{"text/html"}},
+		{Method: "POST", Path: "/unsubscribe", Tag: "notifications", Summary: "Turn digest emails off in one click from a mail client", Query: []*openapi.Parameter{unsubscribeToken}, Produces: []string{"text/html"}},
+
+		{Method: "POST", Path: "/batch", Tag: "batch", Summary: "Run several requests, optionally in one transaction", Auth: true, Request: handler.BatchRequest{}, Response: &handler.BatchResponse{}},
+	}
+
+	idempotencyKey := openapi.HeaderParam("Idempotency-Key", "Makes the request safe to retry; retries with the same key replay the first response.")
+
+	for _, op := range ops {
+		if op.Method == http.MethodPost && op.Auth {
+			op.Headers = append(op.Headers, idempotencyKey)
+		}
+
+		unversioned := op
+		unversioned.Deprecated = true
+
+		op.Path = "/v1" + op.Path
+		b.Add(op)
+		b.Add(unversioned)
+	}
+
+	b.Add(openapi.Op{Method: "GET", Path: "/openapi.json", Tag: "meta", Summary: "This document", Produces: []string{"application/json"}})
+	b.Add(openapi.Op{Method: "GET", Path: "/docs", Tag: "meta", Summary: "API reference page", Produces: []string{"text/html"}})
+
+	return b.Document()
+}
+
+// VerifyDocument returns the registered routes doc does not describe.
+func (r *Router) VerifyDocument(doc *openapi.Document) ([]string, error) {
+	return openapi.MissingRoutes(r.mux, doc)
+}
@@ -0,0 +1,27 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func TestAPIDocumentDescribesEveryRoute(t *testing.T) {
+	// Handlers are only constructed, never called, so the routes can be
+	// registered without a database or cache.
+	r := NewRouter(&config.AppFlags{})
+	r.RegisterRoutes(&repository.Repository{}, &policy.Policies{}, &cache.Stores{}, middleware.NewMiddlewares(nil, nil, nil))
+
+	missing, err := r.VerifyDocument(APIDocument())
+	if err != nil {
+		t.Fatalf("VerifyDocument: %v", err)
+	}
+
+	for _, route := range missing {
+		t.Errorf("route missing from openapi document: %s", route)
+	}
+}
@@ -4,21 +4,25 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddlewares "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
 	"github.com/mithileshgupta12/velaris/internal/db/policy"
 	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
 	"github.com/mithileshgupta12/velaris/internal/middleware"
 )
 
 type Router struct {
 	mux *chi.Mux
+	app *config.AppFlags
 }
 
-func NewRouter(frontendUrl string) *Router {
+func NewRouter(app *config.AppFlags) *Router {
 	mux := chi.NewRouter()
 
 	mux.Use(chiMiddlewares.RequestID)
@@ -26,44 +30,216 @@ func NewRouter(frontendUrl string) *Router {
 	mux.Use(chiMiddlewares.Logger)
 	mux.Use(chiMiddlewares.Recoverer)
 	mux.Use(middleware.LimitBodySize(1024 * 1024))
+	mux.Use(middleware.ETag)
 
 	mux.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{frontendUrl},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   []string{app.FrontendUrl},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-None-Match", "X-CSRF-Token", handler.SharePasswordHeader},
+		ExposedHeaders:   []string{"Deprecation", "ETag", "Idempotent-Replayed", "Link", "Sunset"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	return &Router{mux}
+	return &Router{mux, app}
 }
 
+// unversionedDeprecation applies to the routes still served without a version
+// prefix for clients written before /v1 existed.
+var unversionedDeprecation = middleware.Deprecation{
+	Since:     time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+	Sunset:    time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+	Successor: "/v1",
+}
+
+// RegisterRoutes mounts every API version under its prefix, e.g. /v1/boards,
+// and the documentation at the root.
 func (r *Router) RegisterRoutes(
 	repositories *repository.Repository,
 	policies *policy.Policies,
 	stores *cache.Stores,
 	middlewares middleware.Middlewares,
 ) {
-	BoardRoutes(
-		r.mux,
-		repositories.BoardRepository,
-		policies.BoardPolicy,
-		middlewares,
-	)
-	AuthRoutes(
-		r.mux,
-		repositories.UserRepository,
-		stores.SessionStore,
-		middlewares,
-	)
-	ListRoutes(
-		r.mux,
-		repositories.ListRepository,
-		policies.BoardPolicy,
-		policies.ListPolicy,
-		middlewares,
-	)
+	deps := &routeDeps{r.app, repositories, policies, stores, middlewares}
+
+	// transact serves the operations of atomic batches with repositories and
+	// policies bound to one transaction.
+	transact := func(run func(api http.Handler) error) error {
+		return repositories.Transaction(func(tx *repository.Repository) error {
+			api := chi.NewRouter()
+			api.Use(middleware.ETag)
+
+			txDeps := &routeDeps{deps.app, tx, policy.InitPolicies(tx.DB()), stores, middlewares}
+			mountVersions(api, txDeps, laterVersions, nil)
+
+			return run(api)
+		})
+	}
+
+	mountVersions(r.mux, deps, laterVersions, func(version chi.Router) {
+		BatchRoutes(version, r.mux, transact, middlewares)
+	})
+
+	OpenAPIRoutes(r.mux, APIDocument())
+}
+
+// routeDeps are what the route functions of every version are built from.
+type routeDeps struct {
+	app          *config.AppFlags
+	repositories *repository.Repository
+	policies     *policy.Policies
+	stores       *cache.Stores
+	middlewares  middleware.Middlewares
+}
+
+// resourceRoutes registers the routes of one resource of a version.
+type resourceRoutes struct {
+	name     string
+	register func(r chi.Router, deps *routeDeps)
+}
+
+// apiVersion is an API version mounted under prefix. Versions after v1 only
+// list the resources they change or add; every other resource falls back to
+// its v1 routes.
+type apiVersion struct {
+	prefix    string
+	resources []resourceRoutes
+}
+
+// laterVersions are the versions mounted next to /v1, e.g.
+//
+//	{prefix: "/v2", resources: []resourceRoutes{{"boards", registerV2BoardRoutes}}}
+var laterVersions []apiVersion
+
+// mountVersions mounts v1 under /v1 and, deprecated, at the root, and each of
+// versions under its prefix. shared, when set, registers routes every
+// version serves alike.
+func mountVersions(mux chi.Router, deps *routeDeps, versions []apiVersion, shared func(r chi.Router)) {
+	register := func(version apiVersion) func(r chi.Router) {
+		return func(r chi.Router) {
+			version.register(r, deps)
+			if shared != nil {
+				shared(r)
+			}
+		}
+	}
+
+	v1 := apiVersion{prefix: "/v1", resources: v1Resources}
+
+	mux.Route(v1.prefix, register(v1))
+	for _, version := range versions {
+		mux.Route(version.prefix, register(version))
+	}
+
+	mux.Group(func(r chi.Router) {
+		r.Use(middleware.Deprecated(unversionedDeprecation))
+
+		register(v1)(r)
+	})
+}
+
+// register registers the resources of v, and the v1 routes of the resources
+// v leaves out.
+func (v apiVersion) register(r chi.Router, deps *routeDeps) {
+	changed := make(map[string]bool, len(v.resources))
+	for _, resource := range v.resources {
+		resource.register(r, deps)
+		changed[resource.name] = true
+	}
+
+	for _, resource := range v1Resources {
+		if !changed[resource.name] {
+			resource.register(r, deps)
+		}
+	}
+}
+
+// v1Resources are the routes of v1, which later versions fall back to.
+var v1Resources = []resourceRoutes{
+	{"boards", func(r chi.Router, d *routeDeps) {
+		BoardRoutes(
+			r,
+			d.repositories.BoardRepository,
+			d.repositories.ListRepository,
+			d.repositories.WatcherRepository,
+			d.policies.BoardPolicy,
+			d.middlewares,
+		)
+	}},
+	{"auth", func(r chi.Router, d *routeDeps) {
+		AuthRoutes(
+			r,
+			d.app,
+			d.repositories,
+			d.stores.SessionStore,
+			d.middlewares,
+		)
+	}},
+	{"lists", func(r chi.Router, d *routeDeps) {
+		ListRoutes(
+			r,
+			d.repositories.ListRepository,
+			d.repositories.BoardRepository,
+			d.repositories.WatcherRepository,
+			d.policies.BoardPolicy,
+			d.policies.ListPolicy,
+			d.middlewares,
+		)
+	}},
+	{"templates", func(r chi.Router, d *routeDeps) {
+		TemplateRoutes(
+			r,
+			d.repositories.BoardRepository,
+			d.repositories.ListRepository,
+			d.middlewares,
+		)
+	}},
+	{"search", func(r chi.Router, d *routeDeps) {
+		SearchRoutes(
+			r,
+			d.repositories.SearchRepository,
+			d.policies.BoardPolicy,
+			d.middlewares,
+		)
+	}},
+	{"webhooks", func(r chi.Router, d *routeDeps) {
+		WebhookRoutes(
+			r,
+			d.repositories.WebhookRepository,
+			d.policies.BoardPolicy,
+			d.middlewares,
+		)
+	}},
+	{"board_members", func(r chi.Router, d *routeDeps) {
+		BoardMemberRoutes(
+			r,
+			d.app,
+			d.repositories.BoardMemberRepository,
+			d.repositories.BoardInvitationRepository,
+			d.policies.BoardPolicy,
+			d.middlewares,
+		)
+	}},
+	{"board_share_links", func(r chi.Router, d *routeDeps) {
+		BoardShareLinkRoutes(
+			r,
+			d.repositories.BoardShareLinkRepository,
+			d.repositories.BoardRepository,
+			d.policies.BoardPolicy,
+			d.stores.AttemptLimiter,
+			d.middlewares,
+		)
+	}},
+	{"notifications", func(r chi.Router, d *routeDeps) {
+		NotificationRoutes(
+			r,
+			d.app,
+			d.repositories.NotificationRepository,
+			d.repositories.DigestRepository,
+			d.stores.NotificationBroker,
+			d.middlewares,
+		)
+	}},
 }
 
 func (r *Router) Serve(port int) error {
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/cache"
+	"github.com/mithileshgupta12/velaris/internal/config"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func TestMountVersionsFallsBackToV1Routes(t *testing.T) {
+	v2 := apiVersion{prefix: "/v2", resources: []resourceRoutes{
+		{"boards", func(r chi.Router, d *routeDeps) {
+			r.Get("/boards", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+		}},
+	}}
+
+	mux := chi.NewRouter()
+	deps := &routeDeps{&config.AppFlags{}, &repository.Repository{}, &policy.Policies{}, &cache.Stores{}, middleware.NewMiddlewares(nil, nil, nil)}
+	mountVersions(mux, deps, []apiVersion{v2}, nil)
+
+	if !mux.Match(chi.NewRouteContext(), http.MethodGet, "/v2/templates") {
+		t.Error("v2 does not fall back to the v1 templates routes")
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/boards", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("GET /v2/boards = %d, want the v2 route", rec.Code)
+	}
+
+	if mux.Match(chi.NewRouteContext(), http.MethodGet, "/v2/boards/1") {
+		t.Error("v2 serves v1 boards routes it replaced")
+	}
+	if !mux.Match(chi.NewRouteContext(), http.MethodGet, "/v1/boards/1") {
+		t.Error("v1 lost the boards routes v2 replaced")
+	}
+}
@@ -0,0 +1,24 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func SearchRoutes(
+	r chi.Router,
+	searchRepository repository.SearchRepository,
+	boardPolicy policy.BoardPolicy,
+	middlewares middleware.Middlewares,
+) {
+	searchHandler := handler.NewSearchHandler(searchRepository, boardPolicy)
+
+	r.Route("/search", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+
+		r.Get("/", searchHandler.Search)
+	})
+}
@@ -0,0 +1,23 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func TemplateRoutes(
+	r chi.Router,
+	boardRepository repository.BoardRepository,
+	listRepository repository.ListRepository,
+	middlewares middleware.Middlewares,
+) {
+	templateHandler := handler.NewTemplateHandler(boardRepository, listRepository)
+
+	r.Route("/templates", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+
+		r.Get("/", templateHandler.Index)
+	})
+}
@@ -0,0 +1,29 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mithileshgupta12/velaris/internal/db/policy"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/handler"
+	"github.com/mithileshgupta12/velaris/internal/middleware"
+)
+
+func WebhookRoutes(
+	r chi.Router,
+	webhookRepository repository.WebhookRepository,
+	boardPolicy policy.Policy,
+	middlewares middleware.Middlewares,
+) {
+	webhookHandler := handler.NewWebhookHandler(webhookRepository, boardPolicy)
+
+	r.Route("/boards/{boardId}/webhooks", func(r chi.Router) {
+		r.Use(middlewares.AuthMiddleware)
+		r.Use(middlewares.IdempotencyMiddleware)
+
+		r.Get("/", webhookHandler.Index)
+		r.Post("/", webhookHandler.Store)
+		r.Get("/{id}", webhookHandler.Show)
+		r.Delete("/{id}", webhookHandler.Destroy)
+		r.Get("/{id}/deliveries", webhookHandler.Deliveries)
+	})
+}
@@ -0,0 +1,76 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+func init() {
+	RegisterExporter("csv", csvExporter{})
+}
+
+// csvExporter writes one row per list so the board can be opened in a
+// spreadsheet.
+type csvExporter struct{}
+
+func (csvExporter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (csvExporter) Export(w io.Writer, board *models.Board) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"board_id",
+		"board_name",
+		"list_id",
+		"list_name",
+		"list_position",
+		"list_archived_at",
+		"list_created_at",
+		"list_updated_at",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, list := range board.Lists {
+		archivedAt := ""
+		if list.ArchivedAt != nil {
+			archivedAt = list.ArchivedAt.UTC().Format(time.RFC3339)
+		}
+
+		record := []string{
+			strconv.FormatInt(board.Id, 10),
+			csvText(board.Name),
+			strconv.FormatInt(list.Id, 10),
+			csvText(list.Name),
+			strconv.Itoa(list.Position),
+			archivedAt,
+			list.CreatedAt.UTC().Format(time.RFC3339),
+			list.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText quotes user text that a spreadsheet would run as a formula, which
+// starts with one of = + - @ or a tab or carriage return, by prefixing it
+// with an apostrophe.
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+
+	return text
+}
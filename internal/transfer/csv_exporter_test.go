@@ -0,0 +1,23 @@
+package transfer
+
+import "testing"
+
+func TestCsvTextNeutralizesFormulas(t *testing.T) {
+	tests := map[string]string{
+		"Roadmap":           "Roadmap",
+		"":                  "",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-2+3":              "'-2+3",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\t=1":              "'\t=1",
+		"Q3 = planning":     "Q3 = planning",
+		"email@example.com": "email@example.com",
+	}
+
+	for text, want := range tests {
+		if got := csvText(text); got != want {
+			t.Errorf("csvText(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
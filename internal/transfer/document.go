@@ -0,0 +1,243 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/apperror"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+)
+
+const (
+	// Format identifies a Velaris board document.
+	Format = "velaris.board"
+	// Version is the document version written by NewDocument. Bump it when
+	// the document shape changes in a way older importers cannot read.
+	Version = 1
+)
+
+// ErrUnsupportedVersion is returned for documents written by a newer Velaris,
+// whose fields this version may not know.
+var ErrUnsupportedVersion = apperror.New(apperror.KindUnprocessable, "unsupported_document_version", fmt.Sprintf("board document version is newer than the supported version %d", Version))
+
+// Document is the self-describing representation of a board used to move
+// boards between Velaris instances.
+type Document struct {
+	Format     string         `json:"format"`
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Board      *BoardDocument `json:"board"`
+}
+
+type BoardDocument struct {
+	Id          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	IsTemplate  bool            `json:"is_template"`
+	ArchivedAt  *time.Time      `json:"archived_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Lists       []*ListDocument `json:"lists"`
+}
+
+type ListDocument struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Position   int        `json:"position"`
+	ArchivedAt *time.Time `json:"archived_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ItemError describes a problem with a single item of a document. Path points
+// at the offending item, e.g. "board.lists[2].name".
+type ItemError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// IdMap maps the ids found in an imported document to the ids of the rows
+// created for them.
+type IdMap struct {
+	Boards map[int64]int64 `json:"boards"`
+	Lists  map[int64]int64 `json:"lists"`
+}
+
+func NewDocument(board *models.Board, lists []*models.List) *Document {
+	listDocuments := make([]*ListDocument, 0, len(lists))
+	for _, list := range lists {
+		listDocuments = append(listDocuments, &ListDocument{
+			Id:         list.Id,
+			Name:       list.Name,
+			Position:   list.Position,
+			ArchivedAt: list.ArchivedAt,
+			CreatedAt:  list.CreatedAt,
+			UpdatedAt:  list.UpdatedAt,
+		})
+	}
+
+	return &Document{
+		Format:     Format,
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		Board: &BoardDocument{
+			Id:          board.Id,
+			Name:        board.Name,
+			Description: board.Description,
+			IsTemplate:  board.IsTemplate,
+			ArchivedAt:  board.ArchivedAt,
+			CreatedAt:   board.CreatedAt,
+			UpdatedAt:   board.UpdatedAt,
+			Lists:       listDocuments,
+		},
+	}
+}
+
+// DecodeDocument reads a document from r. Documents of a newer version are
+// refused with ErrUnsupportedVersion before their fields are looked at, and
+// unknown fields of the others are rejected so that nothing is silently
+// dropped.
+func DecodeDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var document Document
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// Validate checks the document against the schema of its version and returns
+// every problem found.
+func (d *Document) Validate() []*ItemError {
+	errs := []*ItemError{}
+
+	if d.Format != Format {
+		errs = append(errs, &ItemError{"format", fmt.Sprintf("format must be %q", Format)})
+	}
+
+	if d.Version < 1 || d.Version > Version {
+		errs = append(errs, &ItemError{"version", fmt.Sprintf("version %d is not supported", d.Version)})
+	}
+
+	if d.Board == nil {
+		errs = append(errs, &ItemError{"board", "board is a required field"})
+		return errs
+	}
+
+	name := strings.TrimSpace(d.Board.Name)
+	if name == "" {
+		errs = append(errs, &ItemError{"board.name", "name is a required field"})
+	} else if len(name) > 255 {
+		errs = append(errs, &ItemError{"board.name", "name must not be more than 255 characters long"})
+	}
+
+	if d.Board.Description != nil && len(strings.TrimSpace(*d.Board.Description)) > 10000 {
+		errs = append(errs, &ItemError{"board.description", "description must not be more than 10,000 characters long"})
+	}
+
+	listIds := make(map[int64]bool, len(d.Board.Lists))
+	for i, list := range d.Board.Lists {
+		path := fmt.Sprintf("board.lists[%d]", i)
+
+		if list == nil {
+			errs = append(errs, &ItemError{path, "list must be an object"})
+			continue
+		}
+
+		if list.Id != 0 {
+			if listIds[list.Id] {
+				errs = append(errs, &ItemError{path + ".id", fmt.Sprintf("id %d is used by more than one list", list.Id)})
+			}
+			listIds[list.Id] = true
+		}
+
+		listName := strings.TrimSpace(list.Name)
+		if listName == "" {
+			errs = append(errs, &ItemError{path + ".name", "name is a required field"})
+		} else if len(listName) > 255 {
+			errs = append(errs, &ItemError{path + ".name", "name must not be more than 255 characters long"})
+		}
+
+		if list.Position < 1 {
+			errs = append(errs, &ItemError{path + ".position", "position must be at least 1"})
+		}
+	}
+
+	return errs
+}
+
+// CreateBoardArgs maps a validated document onto the arguments creating the
+// board and its lists for userId, keeping their timestamps.
+func (d *Document) CreateBoardArgs(userId int64) *repository.CreateBoardArgs {
+	var description *string
+	if d.Board.Description != nil && strings.TrimSpace(*d.Board.Description) != "" {
+		description = d.Board.Description
+	}
+
+	lists := make([]*repository.CreateBoardListArgs, 0, len(d.Board.Lists))
+	for _, list := range d.Board.Lists {
+		lists = append(lists, &repository.CreateBoardListArgs{
+			Name:       strings.TrimSpace(list.Name),
+			Position:   list.Position,
+			ArchivedAt: list.ArchivedAt,
+			CreatedAt:  list.CreatedAt,
+			UpdatedAt:  list.UpdatedAt,
+		})
+	}
+
+	return &repository.CreateBoardArgs{
+		Name:        strings.TrimSpace(d.Board.Name),
+		Description: description,
+		UserId:      userId,
+		IsTemplate:  d.Board.IsTemplate,
+		ArchivedAt:  d.Board.ArchivedAt,
+		CreatedAt:   d.Board.CreatedAt,
+		UpdatedAt:   d.Board.UpdatedAt,
+		Lists:       lists,
+	}
+}
+
+// IdMap pairs the ids of the document with the board created from it. board
+// must have been created from CreateBoardArgs so its lists are in document
+// order.
+func (d *Document) IdMap(board *models.Board) *IdMap {
+	idMap := &IdMap{
+		Boards: map[int64]int64{},
+		Lists:  map[int64]int64{},
+	}
+
+	if d.Board.Id != 0 {
+		idMap.Boards[d.Board.Id] = board.Id
+	}
+
+	for i, list := range d.Board.Lists {
+		if list.Id != 0 && i < len(board.Lists) {
+			idMap.Lists[list.Id] = board.Lists[i].Id
+		}
+	}
+
+	return idMap
+}
@@ -0,0 +1,49 @@
+package transfer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeDocumentChecksVersionFirst(t *testing.T) {
+	newer := `{"format":"velaris.board","version":2,"board":{"name":"Roadmap","labels":[]}}`
+	if _, err := DecodeDocument(strings.NewReader(newer)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("newer document: err = %v, want %v", err, ErrUnsupportedVersion)
+	}
+
+	unknownField := `{"format":"velaris.board","version":1,"board":{"name":"Roadmap","labels":[]}}`
+	_, err := DecodeDocument(strings.NewReader(unknownField))
+	if err == nil || errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("unknown field: err = %v, want a decoding error", err)
+	}
+}
+
+func TestCreateBoardArgsKeepsTimestamps(t *testing.T) {
+	createdAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(48 * time.Hour)
+
+	document, err := DecodeDocument(strings.NewReader(`{
+		"format": "velaris.board",
+		"version": 1,
+		"board": {
+			"name": "Roadmap",
+			"created_at": "2024-03-01T09:00:00Z",
+			"updated_at": "2024-03-03T09:00:00Z",
+			"lists": [{"name": "Todo", "position": 1, "created_at": "2024-03-01T09:00:00Z", "updated_at": "2024-03-03T09:00:00Z"}]
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("DecodeDocument: %v", err)
+	}
+
+	args := document.CreateBoardArgs(1)
+
+	if !args.CreatedAt.Equal(createdAt) || !args.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("board timestamps = %v, %v, want %v, %v", args.CreatedAt, args.UpdatedAt, createdAt, updatedAt)
+	}
+	if list := args.Lists[0]; !list.CreatedAt.Equal(createdAt) || !list.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("list timestamps = %v, %v, want %v, %v", list.CreatedAt, list.UpdatedAt, createdAt, updatedAt)
+	}
+}
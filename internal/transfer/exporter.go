@@ -0,0 +1,53 @@
+package transfer
+
+import (
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+// Exporter writes a board in a single export format. The board passed to
+// Export has its Lists loaded in position order.
+type Exporter interface {
+	ContentType() string
+	Export(w io.Writer, board *models.Board) error
+}
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]Exporter{}
+)
+
+// RegisterExporter makes an exporter available under format, which is also
+// the file extension of the exported file. Registering a format twice
+// replaces the earlier exporter.
+func RegisterExporter(format string, exporter Exporter) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+
+	exporters[format] = exporter
+}
+
+func GetExporter(format string) (Exporter, bool) {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+
+	exporter, ok := exporters[format]
+	return exporter, ok
+}
+
+// ExportFormats returns the registered formats in alphabetical order.
+func ExportFormats() []string {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+
+	return formats
+}
@@ -0,0 +1,23 @@
+package transfer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+func init() {
+	RegisterExporter("json", jsonExporter{})
+}
+
+// jsonExporter writes the versioned document that POST /boards/import reads.
+type jsonExporter struct{}
+
+func (jsonExporter) ContentType() string {
+	return "application/json"
+}
+
+func (jsonExporter) Export(w io.Writer, board *models.Board) error {
+	return json.NewEncoder(w).Encode(NewDocument(board, board.Lists))
+}
@@ -0,0 +1,90 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+func init() {
+	RegisterExporter("md", markdownExporter{})
+}
+
+// markdownExporter writes the board as a document that can be pasted into a
+// wiki page, with one section per list.
+type markdownExporter struct{}
+
+func (markdownExporter) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+func (markdownExporter) Export(w io.Writer, board *models.Board) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintf(writer, "# %s\n", escapeMarkdown(board.Name))
+
+	if board.ArchivedAt != nil {
+		fmt.Fprintf(writer, "\n_Archived on %s_\n", board.ArchivedAt.UTC().Format("2006-01-02"))
+	}
+
+	if board.Description != nil && *board.Description != "" {
+		fmt.Fprintf(writer, "\n%s\n", escapeMarkdownBlock(*board.Description))
+	}
+
+	for _, list := range board.Lists {
+		fmt.Fprintf(writer, "\n## %d. %s", list.Position, escapeMarkdown(list.Name))
+		if list.ArchivedAt != nil {
+			fmt.Fprint(writer, " (archived)")
+		}
+		fmt.Fprintln(writer)
+	}
+
+	return writer.Flush()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"#", `\#`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+)
+
+var (
+	// lineBreaker turns line breaks into spaces.
+	lineBreaker = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+	// blockMarker matches what starts a list, setext heading or fence at the
+	// beginning of a line, once escapeMarkdown has run.
+	blockMarker = regexp.MustCompile(`^(\s*)([-+=~])`)
+	// orderedMarker matches the number starting an ordered list item.
+	orderedMarker = regexp.MustCompile(`^(\s*\d+)([.)])`)
+)
+
+// escapeMarkdown escapes characters that would otherwise turn names into
+// markdown formatting or HTML. Line breaks become spaces so that a name
+// cannot leave the heading it is written in.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(lineBreaker.Replace(s))
+}
+
+// escapeMarkdownBlock escapes multi-line text like escapeMarkdown, keeping its
+// lines and escaping the markers that would start a block on any of them.
+func escapeMarkdownBlock(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = escapeMarkdown(line)
+		line = blockMarker.ReplaceAllString(line, `$1\$2`)
+		lines[i] = orderedMarker.ReplaceAllString(line, `$1\$2`)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,33 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+)
+
+func TestMarkdownExportKeepsNamesInTheirHeadings(t *testing.T) {
+	description := "Plans\n- [x](javascript:alert(1))\n1. first\n<script>alert(1)</script>\n---"
+	board := &models.Board{
+		Name:        "Roadmap\n<script>alert(1)</script>",
+		Description: &description,
+		Lists: []*models.List{
+			{Name: "Todo\n- [x](javascript:alert(1))", Position: 1},
+			{Name: "Done\r\n# Owned", Position: 2},
+		},
+	}
+
+	var out strings.Builder
+	if err := (markdownExporter{}).Export(&out, board); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	want := "# Roadmap \\<script\\>alert(1)\\</script\\>\n" +
+		"\nPlans\n\\- \\[x\\](javascript:alert(1))\n1\\. first\n\\<script\\>alert(1)\\</script\\>\n\\---\n" +
+		"\n## 1. Todo - \\[x\\](javascript:alert(1))\n" +
+		"\n## 2. Done \\# Owned\n"
+	if got := out.String(); got != want {
+		t.Errorf("Export wrote\n%s\nwant\n%s", got, want)
+	}
+}
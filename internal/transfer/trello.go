@@ -0,0 +1,213 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+)
+
+var (
+	ErrTrelloBoardNameRequired    = errors.New("trello board has no name")
+	ErrTrelloBoardNameTooLong     = errors.New("trello board name is more than 255 characters long")
+	ErrTrelloBoardDescTooLong     = errors.New("trello board description is more than 10,000 characters long")
+	ErrTrelloBoardExportMalformed = errors.New("file is not a trello board export")
+)
+
+// TrelloBoard holds the parts of a Trello board JSON export that Velaris
+// understands. Collections Velaris has no equivalent for are only counted.
+type TrelloBoard struct {
+	Id         string            `json:"id"`
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Closed     bool              `json:"closed"`
+	Lists      []*TrelloList     `json:"lists"`
+	Cards      []json.RawMessage `json:"cards"`
+	Checklists []json.RawMessage `json:"checklists"`
+	Labels     []json.RawMessage `json:"labels"`
+}
+
+type TrelloList struct {
+	Id     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+// TrelloImportReport describes what an import creates and what it skips.
+// Ids are only set once the import has been carried out.
+type TrelloImportReport struct {
+	DryRun  bool                 `json:"dry_run"`
+	Board   *TrelloReportBoard   `json:"board"`
+	Lists   []*TrelloReportList  `json:"lists"`
+	Skipped []*TrelloSkippedItem `json:"skipped"`
+}
+
+type TrelloReportBoard struct {
+	Id       int64  `json:"id,omitempty"`
+	TrelloId string `json:"trello_id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+}
+
+type TrelloReportList struct {
+	Id       int64  `json:"id,omitempty"`
+	TrelloId string `json:"trello_id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Archived bool   `json:"archived"`
+}
+
+type TrelloSkippedItem struct {
+	Kind     string `json:"kind"`
+	TrelloId string `json:"trello_id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Count    int    `json:"count,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+func DecodeTrelloBoard(r io.Reader) (*TrelloBoard, error) {
+	var board TrelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, err
+	}
+
+	if board.Id == "" && board.Lists == nil {
+		return nil, ErrTrelloBoardExportMalformed
+	}
+
+	return &board, nil
+}
+
+// PlanTrelloImport maps a Trello board onto the arguments creating it for
+// userId. Lists are ordered by their Trello pos and renumbered from 1, and
+// closed boards and lists are imported as archived.
+func PlanTrelloImport(board *TrelloBoard, userId int64) (*repository.CreateBoardArgs, *TrelloImportReport, error) {
+	name := strings.TrimSpace(board.Name)
+	if name == "" {
+		return nil, nil, ErrTrelloBoardNameRequired
+	}
+	if len(name) > 255 {
+		return nil, nil, ErrTrelloBoardNameTooLong
+	}
+
+	desc := strings.TrimSpace(board.Desc)
+	if len(desc) > 10000 {
+		return nil, nil, ErrTrelloBoardDescTooLong
+	}
+
+	now := time.Now()
+
+	args := &repository.CreateBoardArgs{
+		Name:   name,
+		UserId: userId,
+		Lists:  []*repository.CreateBoardListArgs{},
+	}
+	if desc != "" {
+		args.Description = &desc
+	}
+	if board.Closed {
+		args.ArchivedAt = &now
+	}
+
+	report := &TrelloImportReport{
+		Board: &TrelloReportBoard{
+			TrelloId: board.Id,
+			Name:     name,
+			Archived: board.Closed,
+		},
+		Lists:   []*TrelloReportList{},
+		Skipped: []*TrelloSkippedItem{},
+	}
+
+	lists := make([]*TrelloList, 0, len(board.Lists))
+	for _, list := range board.Lists {
+		if list != nil {
+			lists = append(lists, list)
+		}
+	}
+	sort.SliceStable(lists, func(i, j int) bool {
+		return lists[i].Pos < lists[j].Pos
+	})
+
+	position := 0
+	for _, list := range lists {
+		listName := strings.TrimSpace(list.Name)
+
+		if listName == "" {
+			report.Skipped = append(report.Skipped, &TrelloSkippedItem{
+				Kind:     "list",
+				TrelloId: list.Id,
+				Reason:   "list has no name",
+			})
+			continue
+		}
+
+		if len(listName) > 255 {
+			report.Skipped = append(report.Skipped, &TrelloSkippedItem{
+				Kind:     "list",
+				TrelloId: list.Id,
+				Name:     listName,
+				Reason:   "list name is more than 255 characters long",
+			})
+			continue
+		}
+
+		position++
+
+		listArgs := &repository.CreateBoardListArgs{
+			Name:     listName,
+			Position: position,
+		}
+		if list.Closed {
+			listArgs.ArchivedAt = &now
+		}
+		args.Lists = append(args.Lists, listArgs)
+
+		report.Lists = append(report.Lists, &TrelloReportList{
+			TrelloId: list.Id,
+			Name:     listName,
+			Position: position,
+			Archived: list.Closed,
+		})
+	}
+
+	unsupported := []struct {
+		kind  string
+		count int
+	}{
+		{"card", len(board.Cards)},
+		{"checklist", len(board.Checklists)},
+		{"label", len(board.Labels)},
+	}
+	for _, item := range unsupported {
+		if item.count == 0 {
+			continue
+		}
+
+		report.Skipped = append(report.Skipped, &TrelloSkippedItem{
+			Kind:   item.kind,
+			Count:  item.count,
+			Reason: item.kind + "s are not supported by Velaris",
+		})
+	}
+
+	return args, report, nil
+}
+
+// SetCreated records the ids of the board created from the plan the report
+// belongs to.
+func (tr *TrelloImportReport) SetCreated(board *models.Board) {
+	tr.Board.Id = board.Id
+
+	for i, list := range tr.Lists {
+		if i < len(board.Lists) {
+			list.Id = board.Lists[i].Id
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package validation checks request data against declarative rules and
+// collects every violation instead of stopping at the first one.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Code identifies the kind of a violation. Codes are part of the API and must
+// not change once released.
+type Code string
+
+const (
+	CodeRequired     Code = "required"
+	CodeTooShort     Code = "too_short"
+	CodeTooLong      Code = "too_long"
+	CodeInvalidEmail Code = "invalid_email"
+	CodeNotAllowed   Code = "not_allowed"
+	CodeTooSmall     Code = "too_small"
+	CodeTooLarge     Code = "too_large"
+	CodeMismatch     Code = "mismatch"
+	CodeInvalid      Code = "invalid"
+)
+
+// Violation is a single failed rule of a field.
+type Violation struct {
+	Code    Code
+	Message string
+}
+
+// Errors holds the violations of a request by field name. Fields are named as
+// they appear in the request body.
+type Errors struct {
+	fields []string
+	byName map[string][]Violation
+}
+
+func (e *Errors) Error() string {
+	if len(e.fields) == 0 {
+		return "validation failed"
+	}
+
+	return e.byName[e.fields[0]][0].Message
+}
+
+// Fields returns the names of the invalid fields in the order they were
+// checked.
+func (e *Errors) Fields() []string {
+	return e.fields
+}
+
+// Violations returns the violations of field.
+func (e *Errors) Violations(field string) []Violation {
+	return e.byName[field]
+}
+
+// Validator accumulates violations. The zero value is not usable, create one
+// with New.
+type Validator struct {
+	errors *Errors
+}
+
+func New() *Validator {
+	return &Validator{
+		errors: &Errors{byName: map[string][]Violation{}},
+	}
+}
+
+// Add records a violation of field.
+func (v *Validator) Add(field string, code Code, message string) {
+	if _, ok := v.errors.byName[field]; !ok {
+		v.errors.fields = append(v.errors.fields, field)
+	}
+
+	v.errors.byName[field] = append(v.errors.byName[field], Violation{
+		Code:    code,
+		Message: message,
+	})
+}
+
+// Check records a violation of field unless ok.
+func (v *Validator) Check(field string, ok bool, code Code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+// String applies rules to a string field. An empty value only fails Required;
+// the other rules treat it as absent.
+func (v *Validator) String(field, value string, rules ...StringRule) {
+	for _, rule := range rules {
+		if violation := rule(field, value); violation != nil {
+			v.Add(field, violation.Code, violation.Message)
+
+			if violation.Code == CodeRequired {
+				return
+			}
+		}
+	}
+}
+
+// Int applies rules to an optional integer field. A nil value only fails
+// RequiredInt.
+func (v *Validator) Int(field string, value *int, rules ...IntRule) {
+	for _, rule := range rules {
+		if violation := rule(field, value); violation != nil {
+			v.Add(field, violation.Code, violation.Message)
+
+			if violation.Code == CodeRequired {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the collected violations, or nil when there are none.
+func (v *Validator) Err() error {
+	if len(v.errors.fields) == 0 {
+		return nil
+	}
+
+	return v.errors
+}
+
+// StringRule checks a string field and returns its violation, if any.
+type StringRule func(field, value string) *Violation
+
+// Required rejects empty and whitespace-only values.
+func Required() StringRule {
+	return func(field, value string) *Violation {
+		if strings.TrimSpace(value) == "" {
+			return &Violation{CodeRequired, fmt.Sprintf("%s is a required field", field)}
+		}
+
+		return nil
+	}
+}
+
+// MinLength rejects values shorter than n characters.
+func MinLength(n int) StringRule {
+	return func(field, value string) *Violation {
+		if value != "" && utf8.RuneCountInString(value) < n {
+			return &Violation{CodeTooShort, fmt.Sprintf("%s must be at least %d characters long", field, n)}
+		}
+
+		return nil
+	}
+}
+
+// MaxLength rejects values longer than n characters.
+func MaxLength(n int) StringRule {
+	return func(field, value string) *Violation {
+		if utf8.RuneCountInString(value) > n {
+			return &Violation{CodeTooLong, fmt.Sprintf("%s must not be more than %s characters long", field, formatInt(n))}
+		}
+
+		return nil
+	}
+}
+
+// Email rejects values that are not a bare email address.
+func Email() StringRule {
+	return func(field, value string) *Violation {
+		if value == "" {
+			return nil
+		}
+
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return &Violation{CodeInvalidEmail, fmt.Sprintf("%s must be a valid email", field)}
+		}
+
+		return nil
+	}
+}
+
+// OneOf rejects values not in allowed.
+func OneOf(allowed ...string) StringRule {
+	return func(field, value string) *Violation {
+		if value != "" && !slices.Contains(allowed, value) {
+			return &Violation{CodeNotAllowed, fmt.Sprintf("%s must be one of %s", field, strings.Join(allowed, ", "))}
+		}
+
+		return nil
+	}
+}
+
+// Equals rejects values different from other, which is the value of the field
+// named otherField.
+func Equals(otherField, other string) StringRule {
+	return func(field, value string) *Violation {
+		if value != other {
+			return &Violation{CodeMismatch, fmt.Sprintf("%s and %s do not match", otherField, field)}
+		}
+
+		return nil
+	}
+}
+
+// IntRule checks an optional integer field and returns its violation, if any.
+type IntRule func(field string, value *int) *Violation
+
+// RequiredInt rejects a missing value.
+func RequiredInt() IntRule {
+	return func(field string, value *int) *Violation {
+		if value == nil {
+			return &Violation{CodeRequired, fmt.Sprintf("%s is a required field", field)}
+		}
+
+		return nil
+	}
+}
+
+// Min rejects values below n.
+func Min(n int) IntRule {
+	return func(field string, value *int) *Violation {
+		if value != nil && *value < n {
+			return &Violation{CodeTooSmall, fmt.Sprintf("%s must be at least %d", field, n)}
+		}
+
+		return nil
+	}
+}
+
+// Max rejects values above n.
+func Max(n int) IntRule {
+	return func(field string, value *int) *Violation {
+		if value != nil && *value > n {
+			return &Violation{CodeTooLarge, fmt.Sprintf("%s must not be more than %d", field, n)}
+		}
+
+		return nil
+	}
+}
+
+// Range rejects values outside min and max, both inclusive.
+func Range(min, max int) IntRule {
+	return func(field string, value *int) *Violation {
+		if value == nil || (*value >= min && *value <= max) {
+			return nil
+		}
+
+		code := CodeTooLarge
+		if *value < min {
+			code = CodeTooSmall
+		}
+
+		return &Violation{code, fmt.Sprintf("%s must be between %d and %d", field, min, max)}
+	}
+}
+
+// formatInt writes n with thousands separators, matching how limits have
+// always been spelled in error messages.
+func formatInt(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
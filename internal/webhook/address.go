@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for webhook URLs, and dials, reaching
+// addresses inside the network: loopback, private, link-local (which holds
+// the cloud metadata endpoints), shared and unspecified ones.
+var ErrForbiddenAddress = errors.New("webhook address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// netip.Addr.IsPrivate leaves out.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr reports whether deliveries may be sent to addr.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL resolves the host of rawURL and returns ErrForbiddenAddress if
+// any of its addresses is not public. The dispatcher checks again when
+// dialing, since the host may resolve differently by then.
+func CheckURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+
+	return nil
+}
+
+// dialControl is the net.Dialer Control of the dispatcher. It runs after
+// name resolution, on the address actually dialed, so a host rebinding to an
+// internal address after CheckURL is refused too.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
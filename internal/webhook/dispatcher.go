@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/backoff"
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+)
+
+const (
+	// pollInterval is how often the queue is checked for due deliveries.
+	pollInterval = time.Second
+	// claimLimit caps the deliveries sent at once.
+	claimLimit = 20
+	// claimLease must outlast requestTimeout so that a delivery being sent
+	// is not claimed again.
+	claimLease     = time.Minute
+	requestTimeout = 10 * time.Second
+
+	// MaxAttempts is the number of failed attempts after which a delivery is
+	// dead and no longer retried.
+	MaxAttempts = 8
+
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+)
+
+// Dispatcher sends queued deliveries and reschedules the ones that fail.
+type Dispatcher struct {
+	webhookRepository repository.WebhookRepository
+	client            *http.Client
+}
+
+func NewDispatcher(webhookRepository repository.WebhookRepository) *Dispatcher {
+	return newDispatcher(webhookRepository, dialControl)
+}
+
+// newDispatcher returns a Dispatcher dialing through control, which tests
+// leave nil to reach local receivers.
+func newDispatcher(webhookRepository repository.WebhookRepository, control func(network, address string, c syscall.RawConn) error) *Dispatcher {
+	dialer := &net.Dialer{
+		Timeout: requestTimeout,
+		Control: control,
+	}
+
+	return &Dispatcher{
+		webhookRepository: webhookRepository,
+		client: &http.Client{
+			Timeout: requestTimeout,
+			// Deliveries go out directly, never through a proxy of the
+			// environment, so that control sees the address dialed.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: requestTimeout,
+				MaxIdleConnsPerHost: claimLimit,
+			},
+			// A redirect is reported as the failed attempt it is rather
+			// than followed to a URL the board owner did not configure.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Run dispatches due deliveries until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil {
+			slog.Error("failed to dispatch webhook deliveries", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends the deliveries that are due and returns how many were
+// attempted.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookRepository.ClaimWebhookDeliveries(&repository.ClaimWebhookDeliveriesArgs{
+		Limit: claimLimit,
+		Lease: claimLease,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Go(func() {
+			d.deliver(ctx, delivery)
+		})
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	// The webhook was deleted after the delivery was claimed; its
+	// deliveries went with it.
+	if delivery.Webhook == nil {
+		return
+	}
+
+	started := time.Now()
+	statusCode, err := d.send(ctx, delivery)
+	duration := time.Since(started)
+
+	args := &repository.RecordWebhookDeliveryAttemptArgs{
+		DeliveryId: delivery.Id,
+		Duration:   duration,
+	}
+
+	if statusCode != 0 {
+		args.StatusCode = &statusCode
+	}
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		args.Status = models.WebhookDeliverySucceeded
+	case delivery.AttemptCount+1 >= MaxAttempts:
+		args.Status = models.WebhookDeliveryDead
+	default:
+		args.Status = models.WebhookDeliveryPending
+		nextAttemptAt := time.Now().Add(RetryDelay(delivery.AttemptCount + 1))
+		args.NextAttemptAt = &nextAttemptAt
+	}
+
+	if err != nil {
+		message := err.Error()
+		args.Error = &message
+	} else if args.Status != models.WebhookDeliverySucceeded {
+		message := fmt.Sprintf("unexpected status %d", statusCode)
+		args.Error = &message
+	}
+
+	if err := d.webhookRepository.RecordWebhookDeliveryAttempt(args); err != nil {
+		slog.Error("failed to record webhook delivery attempt", "delivery_id", delivery.Id, "err", err)
+	}
+}
+
+// send posts the delivery and returns the response status, or 0 with the
+// error when no response was received.
+func (d *Dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Velaris-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Webhook.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Draining a little of the body lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+
+	return res.StatusCode, nil
+}
+
+// RetryDelay returns how long to wait after the given failed delivery
+// attempt: 30s doubling up to 6h, see backoff.Delay.
+func RetryDelay(attempt int) time.Duration {
+	return backoff.Delay(attempt, baseRetryDelay, maxRetryDelay)
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/models"
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+)
+
+// fakeWebhookRepository hands out the deliveries it holds once and records
+// the attempts reported for them.
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	deliveries []*models.WebhookDelivery
+	attempts   []*repository.RecordWebhookDeliveryAttemptArgs
+}
+
+func (f *fakeWebhookRepository) ClaimWebhookDeliveries(args *repository.ClaimWebhookDeliveriesArgs) ([]*models.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deliveries := f.deliveries
+	f.deliveries = nil
+
+	return deliveries, nil
+}
+
+func (f *fakeWebhookRepository) RecordWebhookDeliveryAttempt(args *repository.RecordWebhookDeliveryAttemptArgs) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attempts = append(f.attempts, args)
+
+	return nil
+}
+
+const testSecret = "0123456789abcdef"
+
+func newTestDelivery(url string, attemptCount int) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		Id:           7,
+		WebhookId:    3,
+		Webhook:      &models.Webhook{Id: 3, Url: url, Secret: testSecret},
+		Event:        "board.updated",
+		Payload:      `{"id":1,"event":"board.updated"}`,
+		Status:       models.WebhookDeliveryPending,
+		AttemptCount: attemptCount,
+	}
+}
+
+// dispatchOnce sends delivery to a dispatcher allowed to reach local
+// receivers and returns the attempt it recorded.
+func dispatchOnce(t *testing.T, delivery *models.WebhookDelivery) *repository.RecordWebhookDeliveryAttemptArgs {
+	t.Helper()
+
+	repo := &fakeWebhookRepository{deliveries: []*models.WebhookDelivery{delivery}}
+
+	n, err := newDispatcher(repo, nil).DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("DispatchDue attempted %d deliveries, want 1", n)
+	}
+	if len(repo.attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(repo.attempts))
+	}
+
+	return repo.attempts[0]
+}
+
+func TestDispatchDueSignsDeliveries(t *testing.T) {
+	var verified bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+
+		verified = err == nil && Verify(testSecret, timestamp, body, r.Header.Get(HeaderSignature))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	attempt := dispatchOnce(t, newTestDelivery(receiver.URL, 0))
+
+	if !verified {
+		t.Error("signature does not verify with the webhook secret")
+	}
+	if attempt.Status != models.WebhookDeliverySucceeded {
+		t.Errorf("status = %q, want %q", attempt.Status, models.WebhookDeliverySucceeded)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusNoContent {
+		t.Errorf("status code = %v, want %d", attempt.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestDispatchDueReschedulesFailures(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	before := time.Now()
+	attempt := dispatchOnce(t, newTestDelivery(receiver.URL, 2))
+	after := time.Now()
+
+	if attempt.Status != models.WebhookDeliveryPending {
+		t.Fatalf("status = %q, want %q", attempt.Status, models.WebhookDeliveryPending)
+	}
+	if attempt.NextAttemptAt == nil {
+		t.Fatal("next attempt is not scheduled")
+	}
+
+	// The third attempt waits RetryDelay(3): 2m plus up to 10% jitter.
+	earliest := before.Add(2 * time.Minute)
+	latest := after.Add(2*time.Minute + 12*time.Second)
+	if attempt.NextAttemptAt.Before(earliest) || attempt.NextAttemptAt.After(latest) {
+		t.Errorf("next attempt at %v, want between %v and %v", attempt.NextAttemptAt, earliest, latest)
+	}
+	if attempt.Error == nil {
+		t.Error("failed attempt has no error")
+	}
+}
+
+func TestDispatchDueKillsExhaustedDeliveries(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	attempt := dispatchOnce(t, newTestDelivery(receiver.URL, MaxAttempts-1))
+
+	if attempt.Status != models.WebhookDeliveryDead {
+		t.Errorf("status = %q, want %q", attempt.Status, models.WebhookDeliveryDead)
+	}
+	if attempt.NextAttemptAt != nil {
+		t.Errorf("dead delivery is scheduled at %v", attempt.NextAttemptAt)
+	}
+}
+
+func TestDispatchDueDoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	attempt := dispatchOnce(t, newTestDelivery(receiver.URL, 0))
+
+	if followed {
+		t.Error("redirect was followed")
+	}
+	if attempt.Status != models.WebhookDeliveryPending {
+		t.Errorf("status = %q, want %q", attempt.Status, models.WebhookDeliveryPending)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("status code = %v, want %d", attempt.StatusCode, http.StatusTemporaryRedirect)
+	}
+}
+
+func TestDispatcherRefusesInternalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("internal receiver was reached")
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepository{deliveries: []*models.WebhookDelivery{newTestDelivery(receiver.URL, 0)}}
+
+	if _, err := NewDispatcher(repo).DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+
+	attempt := repo.attempts[0]
+	if attempt.StatusCode != nil || attempt.Error == nil {
+		t.Errorf("attempt = %+v, want a dial error", attempt)
+	}
+}
@@ -0,0 +1,99 @@
+// Package webhook queues board events for the webhooks subscribed to them and
+// delivers them as signed HTTP requests.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mithileshgupta12/velaris/internal/db/repository"
+	"github.com/mithileshgupta12/velaris/internal/event"
+	"github.com/mithileshgupta12/velaris/internal/outbox"
+)
+
+// Events lists every event a webhook can subscribe to.
+var Events = []string{
+	event.BoardUpdated,
+	event.BoardArchived,
+	event.BoardUnarchived,
+	event.ListCreated,
+	event.ListUpdated,
+	event.ListDeleted,
+	event.ListArchived,
+	event.ListUnarchived,
+}
+
+// ConsumerGroup is the event stream consumer group queuing deliveries.
+const ConsumerGroup = "webhooks"
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Velaris-Event"
+	HeaderDelivery  = "X-Velaris-Delivery"
+	HeaderTimestamp = "X-Velaris-Timestamp"
+	HeaderSignature = "X-Velaris-Signature"
+)
+
+// Payload is the body of a delivery.
+type Payload struct {
+	// Id identifies the event. A delivery retried after a lost response
+	// carries the same id.
+	Id         int64     `json:"id"`
+	Event      string    `json:"event"`
+	BoardId    int64     `json:"board_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is the board or list the event is about.
+	Data any `json:"data"`
+}
+
+// Sign returns the HeaderSignature value for body sent at timestamp, in Unix
+// seconds: "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with the webhook's secret. Signing the timestamp lets receivers
+// reject replayed deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body sent at
+// timestamp, comparing in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// HandleEvent queues a delivery of e to each webhook of its board that
+// subscribes to it. It is the handler of the ConsumerGroup consumer.
+func HandleEvent(webhookRepository repository.WebhookRepository) outbox.Handler {
+	return func(ctx context.Context, e *event.Event) error {
+		if !slices.Contains(Events, e.Name) {
+			return nil
+		}
+
+		payload, err := json.Marshal(&Payload{
+			Id:         e.Id,
+			Event:      e.Name,
+			BoardId:    e.BoardId,
+			OccurredAt: e.OccurredAt,
+			Data:       e.Data,
+		})
+		if err != nil {
+			return err
+		}
+
+		return webhookRepository.EnqueueWebhookDeliveries(&repository.EnqueueWebhookDeliveriesArgs{
+			BoardId: e.BoardId,
+			EventId: e.Id,
+			Event:   e.Name,
+			Payload: string(payload),
+		})
+	}
+}